@@ -3,35 +3,57 @@ package main
 import (
 	"bufio"
 	"context"
+	"flag"
 	"fmt"
 	"food-trucks/packages/models"
 	"food-trucks/packages/services"
+	"food-trucks/packages/stores"
 	"food-trucks/packages/util/rdb"
 	"food-trucks/packages/util/yaml"
 	"os"
+	"strings"
 )
 
 type CliConfig struct {
-	Redis rdb.Config `yaml:"redis"`
+	Storage string     `yaml:"storage"`
+	Redis   rdb.Config `yaml:"redis"`
 }
 
 func main() {
+	status := flag.String("status", "", "comma separated permit statuses to keep, e.g. APPROVED,ISSUED")
+	activeAt := flag.String("active-at", "", "keep permits active at this date, e.g. 2022-01-31 or today")
+	openAt := flag.String("open-at", "", "keep facilities open at this time, e.g. 2022-01-31T12:30 or now")
+	limit := flag.Int("limit", 20, "max facilities to print per search, 0 means no limit")
+	flag.Parse()
+	filter, err := services.ParseFilter(*status, *activeAt, *openAt)
+	if err != nil {
+		panic(err)
+	}
+
 	svc := mustInit()
 	reader := bufio.NewReader(os.Stdin)
 	ctx := context.Background()
 
 	for {
-		fmt.Print("Enter Food Item to search facility: ")
+		fmt.Print("Enter Food Item to search facility (end with ? to list matching items): ")
 		item, err := reader.ReadString('\n')
 		if err != nil {
 			panic(err)
 		}
-		facilities, err := svc.GetByItem(ctx, item)
+		if prefix, ok := strings.CutSuffix(strings.TrimSpace(item), "?"); ok {
+			for _, suggestion := range svc.SuggestItems(prefix, *limit) {
+				fmt.Printf("%s (%d)\n", suggestion.Term, suggestion.Count)
+			}
+			continue
+		}
+		results, err := svc.Search(ctx, item, *limit, filter)
 		if err != nil {
 			panic(err)
 		}
-		for _, facility := range facilities {
-			fmt.Println(facility.Applicant + " " + facility.LocationDescription)
+		for _, result := range results {
+			facility := result.Facility
+			fmt.Printf("%.2f %s %s [%s]\n", result.Score, facility.Applicant, facility.LocationDescription,
+				models.JoinFoodItems(facility.FoodItems))
 		}
 	}
 }
@@ -41,20 +63,14 @@ func mustInit() *services.FacilitySvc {
 	if err != nil {
 		panic(err)
 	}
-	facilityStore := rdb.NewEntityStore[string, models.Facility]("facility", 0, config.Redis).
-		WithGetKey(models.GetFacilityKey)
-	itemFacilityStore := rdb.NewSliceStore[string, models.Facility]("item", 0, config.Redis, facilityStore).
-		WithGetKey(models.GetFacilityKey).WithGetScore(models.GetFacilityScore)
-	geoFacilityStore := rdb.NewGeoStore[string, models.Facility]("geo", 0, config.Redis, facilityStore).
-		WithGetKey(models.GetFacilityKey).WithGetLocation(models.GetFacilityLocation)
-	facilitySvc := &services.FacilitySvc{
-		FacilityStore:     facilityStore,
-		ItemFacilityStore: itemFacilityStore,
-		GeoFacilityStore:  geoFacilityStore,
+	facilitySvc, err := stores.NewFacilitySvc(config.Storage, config.Redis)
+	if err != nil {
+		panic(err)
 	}
-	err = facilitySvc.Seed("./configs/data.csv")
+	report, err := facilitySvc.Sync("./configs/data.csv")
 	if err != nil {
 		panic(err)
 	}
+	fmt.Println("sync:", report)
 	return facilitySvc
 }
@@ -1,18 +1,23 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"food-trucks/packages/controllers"
-	"food-trucks/packages/models"
 	"food-trucks/packages/services"
+	"food-trucks/packages/stores"
 	"food-trucks/packages/util/irisbase"
 	"food-trucks/packages/util/rdb"
 	"food-trucks/packages/util/yaml"
+	"github.com/kataras/iris/v12"
 )
 
 type WebConfig struct {
 	irisbase.AppConfig `yaml:"appConfig"`
-	Redis              rdb.Config `yaml:"redis"`
+	Storage            string                `yaml:"storage"`
+	Redis              rdb.Config            `yaml:"redis"`
+	Admin              controllers.AdminAuth `yaml:"admin"`
+	Source             services.SourceConfig `yaml:"source"`
 }
 
 type App struct {
@@ -22,36 +27,36 @@ type App struct {
 
 type AppBuilder struct {
 	WebConfig
+	// refresher is started by main once the app logger exists
+	refresher *services.Refresher
 }
 
-func (b AppBuilder) BadRequest() []error {
-	return []error{}
-}
-
-func (b AppBuilder) Services() []any {
-	redisConfig := b.WebConfig.Redis
-	fmt.Println("redisConfig:", redisConfig)
-	facilityStore := rdb.NewEntityStore[string, models.Facility]("facility", 0, redisConfig).
-		WithGetKey(models.GetFacilityKey)
-	itemFacilityStore := rdb.NewSliceStore[string, models.Facility]("item", 0, redisConfig, facilityStore).
-		WithGetKey(models.GetFacilityKey).WithGetScore(models.GetFacilityScore)
-	geoFacilityStore := rdb.NewGeoStore[string, models.Facility]("geo", 0, redisConfig, facilityStore).
-		WithGetKey(models.GetFacilityKey).WithGetLocation(models.GetFacilityLocation)
-	facilitySvc := &services.FacilitySvc{
-		FacilityStore:     facilityStore,
-		ItemFacilityStore: itemFacilityStore,
-		GeoFacilityStore:  geoFacilityStore,
+func (b *AppBuilder) Services() []any {
+	fmt.Println("storage:", b.WebConfig.Storage, "redisConfig:", b.WebConfig.Redis)
+	facilitySvc, err := stores.NewFacilitySvc(b.WebConfig.Storage, b.WebConfig.Redis)
+	if err != nil {
+		panic(err)
+	}
+	source, err := services.NewFacilitySource(b.WebConfig.Source)
+	if err != nil {
+		panic(err)
 	}
-	err := facilitySvc.Seed("./configs/data.csv")
+	b.refresher = services.NewRefresher(facilitySvc, source, b.WebConfig.Source.RefreshInterval)
+	report, err := b.refresher.Refresh(context.Background())
 	if err != nil {
 		panic(err)
 	}
-	return []any{facilitySvc}
+	fmt.Println("sync:", report)
+	return []any{facilitySvc, b.WebConfig.Admin, b.refresher}
 }
 
-func (b AppBuilder) Controller() map[string]any {
+func (b *AppBuilder) Controller() map[string]any {
 	return map[string]any{
 		"/facilities/": new(controllers.FacilityCtl),
+		"/items/":      new(controllers.ItemCtl),
+		"/admin/":      new(controllers.AdminCtl),
+		"/dataset/":    new(controllers.DatasetCtl),
+		"/tiles/":      new(controllers.TileCtl),
 	}
 }
 
@@ -63,6 +68,9 @@ func main() {
 	app := &App{
 		WebConfig: *config,
 	}
-	app.App = irisbase.NewIrisApp(config.AppConfig, AppBuilder{WebConfig: *config})
+	builder := &AppBuilder{WebConfig: *config}
+	app.App = irisbase.NewIrisApp(config.AppConfig, builder)
+	stop := builder.refresher.Start(context.Background(), app.IrisApp.Logger())
+	iris.RegisterOnInterrupt(stop)
 	app.Start()
 }
@@ -0,0 +1,85 @@
+package controllers
+
+import (
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"food-trucks/packages/services"
+	"food-trucks/packages/util/errs"
+	"github.com/kataras/iris/v12"
+	"io"
+	"net/http"
+	"path"
+	"strings"
+)
+
+// ErrUnauthorized is returned to admin requests without the configured token, the web app maps it to 401
+var ErrUnauthorized = errs.Unauthorized("unauthorized")
+
+// AdminAuth is the bearer token admin endpoints and facility writes require, an empty token disables them
+type AdminAuth struct {
+	Token string `yaml:"token"`
+}
+
+func (a AdminAuth) Check(ctx iris.Context) error {
+	token, ok := strings.CutPrefix(ctx.GetHeader("Authorization"), "Bearer ")
+	if a.Token == "" || !ok || subtle.ConstantTimeCompare([]byte(token), []byte(a.Token)) != 1 {
+		return ErrUnauthorized
+	}
+	return nil
+}
+
+// maxImportSize limits uploads, data.csv is about 500KB
+const maxImportSize = 32 << 20
+
+type AdminCtl struct {
+	C           iris.Context
+	FacilitySvc *services.FacilitySvc
+	Auth        AdminAuth
+}
+
+/*
+PostImport serves POST /import, replacing the dataset with the uploaded one.
+The body is a csv or a json array of facilities, or a multipart form with the file in field "file";
+the format is ?format=csv|json, or else guessed from the file name or Content-Type.
+*/
+func (a AdminCtl) PostImport() any {
+	if err := a.Auth.Check(a.C); err != nil {
+		return err
+	}
+	req := a.C.Request()
+	req.Body = http.MaxBytesReader(a.C.ResponseWriter(), req.Body, maxImportSize)
+	var r io.Reader = req.Body
+	format := a.C.URLParam("format")
+	if contentType := a.C.GetContentTypeRequested(); strings.HasPrefix(contentType, "multipart/form-data") {
+		file, header, err := a.C.FormFile("file")
+		if err != nil {
+			return fmt.Errorf("%w: %v", services.ErrInvalidArgument, err)
+		}
+		defer file.Close()
+		if format == "" {
+			format = strings.TrimPrefix(path.Ext(header.Filename), ".")
+		}
+		r = file
+	} else if format == "" && strings.Contains(contentType, "json") {
+		format = "json"
+	}
+
+	var report *services.SyncReport
+	var err error
+	switch strings.ToLower(format) {
+	case "", "csv":
+		report, err = a.FacilitySvc.ImportCSV(req.Context(), r)
+	case "json":
+		report, err = a.FacilitySvc.ImportJSON(req.Context(), r)
+	default:
+		return fmt.Errorf("%w: format should be csv or json", services.ErrInvalidArgument)
+	}
+	if maxErr := new(http.MaxBytesError); errors.As(err, &maxErr) {
+		return fmt.Errorf("%w: upload exceeds %d bytes", services.ErrInvalidArgument, maxErr.Limit)
+	}
+	if err != nil {
+		return err
+	}
+	return report
+}
@@ -0,0 +1,16 @@
+package controllers
+
+import (
+	"food-trucks/packages/services"
+	"github.com/kataras/iris/v12"
+)
+
+type DatasetCtl struct {
+	C         iris.Context
+	Refresher *services.Refresher
+}
+
+// GetStatus serves /status, when the dataset was last checked and refreshed from its source
+func (d DatasetCtl) GetStatus() any {
+	return d.Refresher.Status()
+}
@@ -0,0 +1,95 @@
+package controllers
+
+import (
+	"encoding/json"
+	"food-trucks/packages/models"
+	"food-trucks/packages/services"
+	"food-trucks/packages/util/errs"
+	"github.com/kataras/iris/v12"
+	"io"
+	"strings"
+)
+
+const (
+	csvContentType    = "text/csv"
+	ndjsonContentType = "application/x-ndjson"
+)
+
+// ListFormat is how a paged list of facilities is encoded
+type ListFormat int
+
+const (
+	ListJSON ListFormat = iota
+	ListGeoJSON
+	// ListCSV has the columns of data.csv, so an export can be seeded or imported back
+	ListCSV
+	// ListNDJSON has a facility json per line, for tools that read large lists line by line
+	ListNDJSON
+)
+
+// ForList is the format of a paged list, by format or header Accept; exports are only offered for paged lists
+func (q FormatQuery) ForList(ctx iris.Context) (ListFormat, error) {
+	switch strings.ToLower(q.Format) {
+	case "csv":
+		return ListCSV, nil
+	case "ndjson":
+		return ListNDJSON, nil
+	case "":
+		accept := ctx.GetHeader("Accept")
+		switch {
+		case strings.Contains(accept, csvContentType):
+			return ListCSV, nil
+		case strings.Contains(accept, ndjsonContentType):
+			return ListNDJSON, nil
+		}
+	}
+	geoJSON, err := q.GeoJSON(ctx)
+	if err != nil {
+		return ListJSON, errs.InvalidFields(errs.FieldError{Field: "format", Reason: "should be json, geojson, csv or ndjson"})
+	}
+	if geoJSON {
+		return ListGeoJSON, nil
+	}
+	return ListJSON, nil
+}
+
+// streamResponse writes the body as it is encoded, instead of buffering all of it like mvc.Response
+type streamResponse struct {
+	contentType string
+	write       func(w io.Writer) error
+}
+
+func (r streamResponse) Dispatch(ctx iris.Context) {
+	ctx.ContentType(r.contentType)
+	if err := r.write(ctx.ResponseWriter()); err != nil {
+		// the status is sent already, the client sees a truncated body
+		ctx.Application().Logger().Errorf("fail to write %s, %v", r.contentType, err)
+	}
+}
+
+func facilitiesCSV(facilities []models.Facility) any {
+	return streamResponse{contentType: csvContentType, write: func(w io.Writer) error {
+		return services.WriteCSV(w, facilities)
+	}}
+}
+
+// facilitiesNDJSON projects each line to the given fields if any, like the json array
+func facilitiesNDJSON(facilities []models.Facility, fields []string) any {
+	return streamResponse{contentType: ndjsonContentType, write: func(w io.Writer) error {
+		encoder := json.NewEncoder(w)
+		for _, facility := range facilities {
+			var v any = facility
+			if len(fields) > 0 {
+				projected, err := facilityProperties(facility, fields)
+				if err != nil {
+					return err
+				}
+				v = projected
+			}
+			if err := encoder.Encode(v); err != nil {
+				return err
+			}
+		}
+		return nil
+	}}
+}
@@ -1,35 +1,319 @@
 package controllers
 
 import (
+	"fmt"
+	"food-trucks/packages/models"
 	"food-trucks/packages/services"
+	"food-trucks/packages/util/errs"
+	"food-trucks/packages/util/validate"
 	"github.com/kataras/iris/v12"
 )
 
 type FacilityCtl struct {
 	C           iris.Context
 	FacilitySvc *services.FacilitySvc
+	Auth        AdminAuth
 }
 
+/*
+FilterQuery is shared by list endpoints, e.g. ?status=APPROVED,ISSUED&active_at=2022-01-31&open_at=2022-01-31T12:30,
+open_now=true is open_at=now.
+*/
+type FilterQuery struct {
+	Status   string `url:"status"`
+	ActiveAt string `url:"active_at"`
+	OpenAt   string `url:"open_at"`
+	OpenNow  bool   `url:"open_now"`
+}
+
+func (q FilterQuery) Filter() (services.Filter, error) {
+	openAt := q.OpenAt
+	if q.OpenNow {
+		openAt = "now"
+	}
+	return services.ParseFilter(q.Status, q.ActiveAt, openAt)
+}
+
+// GetCenter serves /center, kept for clients before /extent with its fields Lat and Lon, 404 if there is no facility
 func (f FacilityCtl) GetCenter() any {
-	return f.FacilitySvc.Center
+	extent := f.FacilitySvc.GetExtent()
+	if extent.Center == nil {
+		return errs.NotFound("no facility")
+	}
+	return struct{ Lat, Lon float64 }{extent.Center.Lat, extent.Center.Lon}
+}
+
+// GetExtent serves /extent, the box, median center and count of facilities and the zoom a map should open at
+func (f FacilityCtl) GetExtent() any {
+	return f.FacilitySvc.GetExtent()
 }
 
+/*
+Get serves /?lat&lon&radius, facilities within radius (km, 1 by default) of the point.
+lat and lon are given together, without them the point is the median center of all facilities.
+*/
 func (f FacilityCtl) Get(qry struct {
-	Lat    float64 `url:"lat"`
-	Lon    float64 `url:"lon"`
-	Radius float64 `url:"radius"`
+	Lat    *float64 `url:"lat" validate:"min=-90,max=90"`
+	Lon    *float64 `url:"lon" validate:"min=-180,max=180"`
+	Radius float64  `url:"radius" default:"1" validate:"min=0,max=50"`
+	FilterQuery
+	FormatQuery
+	ListQuery
+}) any {
+	if err := validate.Struct(&qry); err != nil {
+		return err
+	}
+	format, err := qry.ForList(f.C)
+	if err != nil {
+		return err
+	}
+	page, err := qry.Page()
+	if err != nil {
+		return err
+	}
+	var lat, lon float64
+	switch {
+	case qry.Lat == nil && qry.Lon == nil:
+		center := f.FacilitySvc.GetCenter()
+		lat, lon = center.Lat, center.Lon
+	case qry.Lat == nil:
+		return errs.InvalidFields(errs.FieldError{Field: "lat", Reason: "is required with lon"})
+	case qry.Lon == nil:
+		return errs.InvalidFields(errs.FieldError{Field: "lon", Reason: "is required with lat"})
+	default:
+		lat, lon = *qry.Lat, *qry.Lon
+	}
+	filter, err := qry.Filter()
+	if err != nil {
+		return err
+	}
+	items, next, err := f.FacilitySvc.GetByLocation(f.C.Request().Context(), lat, lon, qry.Radius, filter, page)
+	if err != nil {
+		return err
+	}
+	return qry.respond(f.C, format, items, next)
+}
+
+// GetNearest serves /nearest?lat&lon&count&radius, count defaults to 10, radius (km) 0 means no limit
+func (f FacilityCtl) GetNearest(qry struct {
+	Lat    *float64 `url:"lat" validate:"required,min=-90,max=90"`
+	Lon    *float64 `url:"lon" validate:"required,min=-180,max=180"`
+	Count  int      `url:"count" default:"10" validate:"min=1,max=100"`
+	Radius float64  `url:"radius" validate:"min=0,max=50"`
+	FilterQuery
+	FormatQuery
+}) any {
+	if err := validate.Struct(&qry); err != nil {
+		return err
+	}
+	geoJSON, err := qry.GeoJSON(f.C)
+	if err != nil {
+		return err
+	}
+	filter, err := qry.Filter()
+	if err != nil {
+		return err
+	}
+	items, err := f.FacilitySvc.GetNearest(f.C.Request().Context(), *qry.Lat, *qry.Lon, qry.Count, qry.Radius, filter)
+	if err != nil {
+		return err
+	}
+	if geoJSON {
+		return distancesGeoJSON(items)
+	}
+	return items
+}
+
+// GetNearby serves /nearby?item&lat&lon&count&radius, facilities serving the item nearest first
+func (f FacilityCtl) GetNearby(qry struct {
+	Item   string   `url:"item" validate:"required"`
+	Lat    *float64 `url:"lat" validate:"required,min=-90,max=90"`
+	Lon    *float64 `url:"lon" validate:"required,min=-180,max=180"`
+	Count  int      `url:"count" default:"10" validate:"min=1,max=100"`
+	Radius float64  `url:"radius" validate:"min=0,max=50"`
+	FilterQuery
+	FormatQuery
+}) any {
+	if err := validate.Struct(&qry); err != nil {
+		return err
+	}
+	geoJSON, err := qry.GeoJSON(f.C)
+	if err != nil {
+		return err
+	}
+	filter, err := qry.Filter()
+	if err != nil {
+		return err
+	}
+	items, err := f.FacilitySvc.GetNearestByItem(f.C.Request().Context(), qry.Item, *qry.Lat, *qry.Lon, qry.Count, qry.Radius, filter)
+	if err != nil {
+		return err
+	}
+	if geoJSON {
+		return distancesGeoJSON(items)
+	}
+	return items
+}
+
+// GetBbox serves /bbox?minLat&minLon&maxLat&maxLon, facilities inside a map viewport
+func (f FacilityCtl) GetBbox(qry struct {
+	MinLat *float64 `url:"minLat" validate:"required,min=-90,max=90"`
+	MinLon *float64 `url:"minLon" validate:"required,min=-180,max=180"`
+	MaxLat *float64 `url:"maxLat" validate:"required,min=-90,max=90"`
+	MaxLon *float64 `url:"maxLon" validate:"required,min=-180,max=180"`
+	FilterQuery
+	FormatQuery
+	ListQuery
+}) any {
+	if err := validate.Struct(&qry); err != nil {
+		return err
+	}
+	format, err := qry.ForList(f.C)
+	if err != nil {
+		return err
+	}
+	page, err := qry.Page()
+	if err != nil {
+		return err
+	}
+	filter, err := qry.Filter()
+	if err != nil {
+		return err
+	}
+	box := services.BBox{MinLat: *qry.MinLat, MinLon: *qry.MinLon, MaxLat: *qry.MaxLat, MaxLon: *qry.MaxLon}
+	items, next, err := f.FacilitySvc.GetByBox(f.C.Request().Context(), box, filter, page)
+	if err != nil {
+		return err
+	}
+	return qry.respond(f.C, format, items, next)
+}
+
+// GetClusters serves /clusters?minLat&minLon&maxLat&maxLon&zoom, clusters of facilities for zoomed out maps
+func (f FacilityCtl) GetClusters(qry struct {
+	MinLat *float64 `url:"minLat" validate:"required,min=-90,max=90"`
+	MinLon *float64 `url:"minLon" validate:"required,min=-180,max=180"`
+	MaxLat *float64 `url:"maxLat" validate:"required,min=-90,max=90"`
+	MaxLon *float64 `url:"maxLon" validate:"required,min=-180,max=180"`
+	Zoom   int      `url:"zoom" validate:"min=0,max=22"`
+	FilterQuery
+	FormatQuery
 }) any {
-	items, err := f.FacilitySvc.GetByLocation(f.C.Request().Context(), qry.Lat, qry.Lon, qry.Radius)
+	if err := validate.Struct(&qry); err != nil {
+		return err
+	}
+	geoJSON, err := qry.GeoJSON(f.C)
+	if err != nil {
+		return err
+	}
+	filter, err := qry.Filter()
+	if err != nil {
+		return err
+	}
+	box := services.BBox{MinLat: *qry.MinLat, MinLon: *qry.MinLon, MaxLat: *qry.MaxLat, MaxLon: *qry.MaxLon}
+	clusters, err := f.FacilitySvc.GetClusters(f.C.Request().Context(), box, qry.Zoom, filter)
+	if err != nil {
+		return err
+	}
+	if geoJSON {
+		return clustersGeoJSON(clusters)
+	}
+	return clusters
+}
+
+// GetSearch serves /search?q&limit, facilities ranked by how well their food items match q
+func (f FacilityCtl) GetSearch(qry struct {
+	Q     string `url:"q" validate:"required,max=100"`
+	Limit int    `url:"limit" validate:"min=0"`
+	FilterQuery
+	FormatQuery
+}) any {
+	if err := validate.Struct(&qry); err != nil {
+		return err
+	}
+	geoJSON, err := qry.GeoJSON(f.C)
+	if err != nil {
+		return err
+	}
+	filter, err := qry.Filter()
+	if err != nil {
+		return err
+	}
+	items, err := f.FacilitySvc.Search(f.C.Request().Context(), qry.Q, qry.Limit, filter)
 	if err != nil {
 		return err
 	}
+	if geoJSON {
+		return searchGeoJSON(items)
+	}
 	return items
 }
 
+// GetBy serves /{locationID}, one facility or 404
 func (f FacilityCtl) GetBy(id string) any {
-	item, err := f.FacilitySvc.GetByItem(f.C.Request().Context(), id)
+	facility, err := f.FacilitySvc.GetByID(f.C.Request().Context(), id)
+	if err != nil {
+		return err
+	}
+	return facility
+}
+
+// Post serves POST /, creating the facility in the body, it requires the admin token
+func (f FacilityCtl) Post() any {
+	if err := f.Auth.Check(f.C); err != nil {
+		return err
+	}
+	var facility models.Facility
+	if err := f.C.ReadJSON(&facility); err != nil {
+		return fmt.Errorf("%w: %v", services.ErrInvalidArgument, err)
+	}
+	facility, err := f.FacilitySvc.CreateFacility(f.C.Request().Context(), facility)
+	if err != nil {
+		return err
+	}
+	f.C.StatusCode(iris.StatusCreated)
+	return facility
+}
+
+// PutBy serves PUT /{id}, replacing the facility with the body, it requires the admin token
+func (f FacilityCtl) PutBy(id string) any {
+	if err := f.Auth.Check(f.C); err != nil {
+		return err
+	}
+	var facility models.Facility
+	if err := f.C.ReadJSON(&facility); err != nil {
+		return fmt.Errorf("%w: %v", services.ErrInvalidArgument, err)
+	}
+	facility, err := f.FacilitySvc.UpdateFacility(f.C.Request().Context(), id, facility)
+	if err != nil {
+		return err
+	}
+	return facility
+}
+
+// PatchBy serves PATCH /{id}, replacing the fields in the body, it requires the admin token
+func (f FacilityCtl) PatchBy(id string) any {
+	if err := f.Auth.Check(f.C); err != nil {
+		return err
+	}
+	patch, err := f.C.GetBody()
 	if err != nil {
+		return fmt.Errorf("%w: %v", services.ErrInvalidArgument, err)
+	}
+	facility, err := f.FacilitySvc.PatchFacility(f.C.Request().Context(), id, patch)
+	if err != nil {
+		return err
+	}
+	return facility
+}
+
+// DeleteBy serves DELETE /{id}, it requires the admin token
+func (f FacilityCtl) DeleteBy(id string) any {
+	if err := f.Auth.Check(f.C); err != nil {
+		return err
+	}
+	if err := f.FacilitySvc.DeleteFacility(f.C.Request().Context(), id); err != nil {
 		return err
 	}
-	return item
+	f.C.StatusCode(iris.StatusNoContent)
+	return nil
 }
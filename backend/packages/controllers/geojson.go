@@ -0,0 +1,141 @@
+package controllers
+
+import (
+	"encoding/json"
+	"food-trucks/packages/models"
+	"food-trucks/packages/services"
+	"food-trucks/packages/util/errs"
+	"github.com/kataras/iris/v12"
+	"github.com/kataras/iris/v12/mvc"
+	"strings"
+)
+
+// geoJSONContentType is the media type of RFC 7946 GeoJSON
+const geoJSONContentType = "application/geo+json"
+
+/*
+FormatQuery is shared by list endpoints, format=geojson or header Accept: application/geo+json
+returns a GeoJSON FeatureCollection of Points with facility fields as properties, instead of the json array.
+Paged lists can also be exported as csv or ndjson, see ForList.
+*/
+type FormatQuery struct {
+	Format string `url:"format"`
+}
+
+// GeoJSON tells if GeoJSON is requested, an unknown format is invalid
+func (q FormatQuery) GeoJSON(ctx iris.Context) (bool, error) {
+	switch strings.ToLower(q.Format) {
+	case "geojson":
+		return true, nil
+	case "":
+		return strings.Contains(ctx.GetHeader("Accept"), geoJSONContentType), nil
+	case "json":
+		return false, nil
+	default:
+		return false, errs.InvalidFields(errs.FieldError{Field: "format", Reason: "should be json or geojson"})
+	}
+}
+
+type geoJSONGeometry struct {
+	Type string `json:"type"`
+	// Coordinates are longitude then latitude
+	Coordinates []float64 `json:"coordinates"`
+}
+
+type geoJSONFeature struct {
+	Type       string          `json:"type"`
+	ID         string          `json:"id,omitempty"`
+	Geometry   geoJSONGeometry `json:"geometry"`
+	Properties map[string]any  `json:"properties"`
+}
+
+type geoJSONCollection struct {
+	Type     string           `json:"type"`
+	Features []geoJSONFeature `json:"features"`
+}
+
+func pointFeature(id string, lat, lon float64, properties map[string]any) geoJSONFeature {
+	return geoJSONFeature{
+		Type:       "Feature",
+		ID:         id,
+		Geometry:   geoJSONGeometry{Type: "Point", Coordinates: []float64{lon, lat}},
+		Properties: properties,
+	}
+}
+
+// facilityFeature has the fields of the facility as properties, only the given ones if any
+func facilityFeature(facility models.Facility, fields []string) (geoJSONFeature, error) {
+	properties, err := facilityProperties(facility, fields)
+	if err != nil {
+		return geoJSONFeature{}, err
+	}
+	return pointFeature(facility.LocationID, facility.Latitude, facility.Longitude, properties), nil
+}
+
+func geoJSONResponse(features []geoJSONFeature) any {
+	bs, err := json.Marshal(geoJSONCollection{Type: "FeatureCollection", Features: features})
+	if err != nil {
+		return errs.Err(err)
+	}
+	return mvc.Response{ContentType: geoJSONContentType, Content: bs}
+}
+
+func facilitiesGeoJSON(facilities []models.Facility, fields []string) any {
+	features := make([]geoJSONFeature, len(facilities))
+	for i, facility := range facilities {
+		feature, err := facilityFeature(facility, fields)
+		if err != nil {
+			return err
+		}
+		features[i] = feature
+	}
+	return geoJSONResponse(features)
+}
+
+// distancesGeoJSON has the distance in meters as a property of each facility
+func distancesGeoJSON(distances []services.FacilityDistance) any {
+	features := make([]geoJSONFeature, len(distances))
+	for i, d := range distances {
+		feature, err := facilityFeature(d.Facility, nil)
+		if err != nil {
+			return err
+		}
+		feature.Properties["distance"] = d.Distance
+		features[i] = feature
+	}
+	return geoJSONResponse(features)
+}
+
+// searchGeoJSON has the relevance as a property of each facility
+func searchGeoJSON(results []services.SearchResult) any {
+	features := make([]geoJSONFeature, len(results))
+	for i, result := range results {
+		feature, err := facilityFeature(result.Facility, nil)
+		if err != nil {
+			return err
+		}
+		feature.Properties["score"] = result.Score
+		features[i] = feature
+	}
+	return geoJSONResponse(features)
+}
+
+// clustersGeoJSON marks cluster features with property cluster, like supercluster does for map libraries
+func clustersGeoJSON(result *services.ClusterResult) any {
+	features := make([]geoJSONFeature, 0, len(result.Clusters)+len(result.Facilities))
+	for _, cluster := range result.Clusters {
+		features = append(features, pointFeature("", cluster.Latitude, cluster.Longitude, map[string]any{
+			"cluster":    true,
+			"count":      cluster.Count,
+			"applicants": cluster.Applicants,
+		}))
+	}
+	for _, facility := range result.Facilities {
+		feature, err := facilityFeature(facility, nil)
+		if err != nil {
+			return err
+		}
+		features = append(features, feature)
+	}
+	return geoJSONResponse(features)
+}
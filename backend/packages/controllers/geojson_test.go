@@ -0,0 +1,161 @@
+package controllers
+
+import (
+	"encoding/json"
+	"food-trucks/packages/models"
+	"food-trucks/packages/services"
+	"food-trucks/packages/util/errs"
+	"github.com/kataras/iris/v12"
+	"github.com/kataras/iris/v12/mvc"
+	"net/http/httptest"
+	"testing"
+)
+
+// newContext is an iris context of a GET to target with header Accept if not empty
+func newContext(target string, accept string) iris.Context {
+	r := httptest.NewRequest("GET", target, nil)
+	if accept != "" {
+		r.Header.Set("Accept", accept)
+	}
+	return iris.New().ContextPool.Acquire(httptest.NewRecorder(), r)
+}
+
+func TestFormatQuery_GeoJSON(t *testing.T) {
+	cases := []struct {
+		format  string
+		accept  string
+		geoJSON bool
+		invalid bool
+	}{
+		{format: "", accept: "", geoJSON: false},
+		{format: "", accept: "application/json", geoJSON: false},
+		{format: "", accept: "application/geo+json, application/json;q=0.9", geoJSON: true},
+		{format: "geojson", accept: "", geoJSON: true},
+		{format: "GeoJSON", accept: "application/json", geoJSON: true},
+		{format: "json", accept: "application/geo+json", geoJSON: false},
+		{format: "csv", invalid: true},
+		{format: "xml", invalid: true},
+	}
+	for _, c := range cases {
+		geoJSON, err := FormatQuery{Format: c.format}.GeoJSON(newContext("/", c.accept))
+		if c.invalid {
+			fields := errs.FieldsOf(err)
+			if errs.KindOf(err) != errs.KindInvalid || len(fields) != 1 || fields[0].Field != "format" {
+				t.Fatalf("expect format %q with Accept %q invalid, got %v", c.format, c.accept, err)
+			}
+			continue
+		}
+		if err != nil || geoJSON != c.geoJSON {
+			t.Fatalf("expect format %q with Accept %q geoJSON %v, got %v %v", c.format, c.accept, c.geoJSON, geoJSON, err)
+		}
+	}
+}
+
+func TestFormatQuery_ForList(t *testing.T) {
+	cases := []struct {
+		format  string
+		accept  string
+		want    ListFormat
+		invalid bool
+	}{
+		{format: "", accept: "", want: ListJSON},
+		{format: "", accept: "application/geo+json", want: ListGeoJSON},
+		{format: "", accept: "text/csv", want: ListCSV},
+		{format: "", accept: "application/x-ndjson", want: ListNDJSON},
+		{format: "csv", accept: "application/geo+json", want: ListCSV},
+		{format: "NDJSON", accept: "", want: ListNDJSON},
+		{format: "json", accept: "text/csv", want: ListJSON},
+		{format: "xml", invalid: true},
+	}
+	for _, c := range cases {
+		format, err := FormatQuery{Format: c.format}.ForList(newContext("/", c.accept))
+		if c.invalid {
+			if fields := errs.FieldsOf(err); len(fields) != 1 || fields[0].Field != "format" {
+				t.Fatalf("expect format %q invalid, got %v", c.format, err)
+			}
+			continue
+		}
+		if err != nil || format != c.want {
+			t.Fatalf("expect format %q with Accept %q to be %v, got %v %v", c.format, c.accept, c.want, format, err)
+		}
+	}
+}
+
+// decodeCollection checks the response is GeoJSON and decodes it
+func decodeCollection(t *testing.T, result any) geoJSONCollection {
+	response, ok := result.(mvc.Response)
+	if !ok {
+		t.Fatalf("expect a response, got %v", result)
+	}
+	if response.ContentType != geoJSONContentType {
+		t.Fatalf("unexpected content type %s", response.ContentType)
+	}
+	var collection geoJSONCollection
+	if err := json.Unmarshal(response.Content, &collection); err != nil {
+		t.Fatal(err)
+	}
+	if collection.Type != "FeatureCollection" {
+		t.Fatalf("unexpected type %s", collection.Type)
+	}
+	return collection
+}
+
+var anzu = models.Facility{
+	LocationID: "1569152", Applicant: "Anzu", Status: models.PermitApproved,
+	FoodItems: []string{"Sliders", "Misubi"}, Latitude: 37.8059, Longitude: -122.4159,
+}
+
+func TestFacilitiesGeoJSON(t *testing.T) {
+	collection := decodeCollection(t, facilitiesGeoJSON([]models.Facility{anzu}, nil))
+	if len(collection.Features) != 1 {
+		t.Fatalf("expect 1 feature, got %v", collection.Features)
+	}
+	feature := collection.Features[0]
+	if feature.Type != "Feature" || feature.ID != anzu.LocationID || feature.Geometry.Type != "Point" {
+		t.Fatalf("unexpected feature %+v", feature)
+	}
+	if c := feature.Geometry.Coordinates; len(c) != 2 || c[0] != anzu.Longitude || c[1] != anzu.Latitude {
+		t.Fatalf("expect coordinates longitude then latitude, got %v", c)
+	}
+	if feature.Properties["applicant"] != "Anzu" || feature.Properties["foodItems"] != "Sliders: Misubi" ||
+		feature.Properties["status"] != "APPROVED" {
+		t.Fatalf("expect facility fields as in the json api, got %v", feature.Properties)
+	}
+
+	projected := decodeCollection(t, facilitiesGeoJSON([]models.Facility{anzu}, []string{"applicant", "status"}))
+	properties := projected.Features[0].Properties
+	if len(properties) != 2 || properties["applicant"] != "Anzu" || properties["status"] != "APPROVED" {
+		t.Fatalf("expect only the given fields, got %v", properties)
+	}
+
+	if empty := decodeCollection(t, facilitiesGeoJSON(nil, nil)); empty.Features == nil || len(empty.Features) != 0 {
+		t.Fatalf("expect features an empty array, got %v", empty.Features)
+	}
+}
+
+func TestDistancesGeoJSON(t *testing.T) {
+	collection := decodeCollection(t, distancesGeoJSON([]services.FacilityDistance{{Facility: anzu, Distance: 12.5}}))
+	if properties := collection.Features[0].Properties; properties["distance"] != 12.5 || properties["applicant"] != "Anzu" {
+		t.Fatalf("expect distance with the facility fields, got %v", properties)
+	}
+}
+
+func TestClustersGeoJSON(t *testing.T) {
+	collection := decodeCollection(t, clustersGeoJSON(&services.ClusterResult{
+		Clusters:   []services.Cluster{{Latitude: 37.77, Longitude: -122.41, Count: 3, Applicants: []string{"A", "B"}}},
+		Facilities: []models.Facility{anzu},
+	}))
+	if len(collection.Features) != 2 {
+		t.Fatalf("expect a cluster and a facility, got %v", collection.Features)
+	}
+	cluster, facility := collection.Features[0], collection.Features[1]
+	if cluster.Properties["cluster"] != true || cluster.Properties["count"] != 3.0 || cluster.ID != "" {
+		t.Fatalf("unexpected cluster %+v", cluster)
+	}
+	if c := cluster.Geometry.Coordinates; c[0] != -122.41 || c[1] != 37.77 {
+		t.Fatalf("expect cluster coordinates longitude then latitude, got %v", c)
+	}
+	if _, ok := facility.Properties["cluster"]; ok || facility.ID != anzu.LocationID {
+		t.Fatalf("expect a facility feature without cluster, got %+v", facility)
+	}
+}
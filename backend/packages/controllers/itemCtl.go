@@ -0,0 +1,51 @@
+package controllers
+
+import (
+	"food-trucks/packages/services"
+	"food-trucks/packages/util/validate"
+	"github.com/kataras/iris/v12"
+)
+
+type ItemCtl struct {
+	C           iris.Context
+	FacilitySvc *services.FacilitySvc
+}
+
+// GetSuggest serves /suggest?q&limit, food items starting with q, most served first
+func (i ItemCtl) GetSuggest(qry struct {
+	Q     string `url:"q"`
+	Limit int    `url:"limit" default:"10" validate:"min=1,max=100"`
+}) any {
+	if err := validate.Struct(&qry); err != nil {
+		return err
+	}
+	return i.FacilitySvc.SuggestItems(qry.Q, qry.Limit)
+}
+
+// GetByFacilities serves /{item}/facilities, facilities serving the food item
+func (i ItemCtl) GetByFacilities(item string, qry struct {
+	FilterQuery
+	FormatQuery
+	ListQuery
+}) any {
+	if err := validate.Struct(&qry); err != nil {
+		return err
+	}
+	format, err := qry.ForList(i.C)
+	if err != nil {
+		return err
+	}
+	page, err := qry.Page()
+	if err != nil {
+		return err
+	}
+	filter, err := qry.Filter()
+	if err != nil {
+		return err
+	}
+	facilities, next, err := i.FacilitySvc.GetByItem(i.C.Request().Context(), item, filter, page)
+	if err != nil {
+		return err
+	}
+	return qry.respond(i.C, format, facilities, next)
+}
@@ -0,0 +1,156 @@
+package controllers
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"food-trucks/packages/models"
+	"food-trucks/packages/services"
+	"food-trucks/packages/util/errs"
+	"github.com/kataras/iris/v12"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+/*
+ListQuery is shared by list endpoints, e.g. ?limit=50&fields=locationID,latitude,longitude.
+The body stays an array; if there are more facilities, the cursor of the next page is in header X-Next-Cursor
+and its url in header Link. Without limit all facilities are returned, without fields all of their fields.
+*/
+type ListQuery struct {
+	Limit  int    `url:"limit" validate:"min=0,max=1000"`
+	Cursor string `url:"cursor"`
+	Fields string `url:"fields"`
+}
+
+// Page validates the cursor and fields, and returns the page they select
+func (q ListQuery) Page() (services.Page, error) {
+	page := services.Page{Limit: q.Limit}
+	var invalid []errs.FieldError
+	if q.Cursor != "" {
+		offset, err := decodeCursor(q.Cursor)
+		if err != nil {
+			invalid = append(invalid, errs.FieldError{Field: "cursor", Reason: "is not a cursor of a list"})
+		}
+		page.Offset = offset
+	}
+	for _, field := range q.fields() {
+		if !facilityFields[field] {
+			invalid = append(invalid, errs.FieldError{Field: "fields", Reason: fmt.Sprintf("has unknown field %q", field)})
+		}
+	}
+	if len(invalid) > 0 {
+		return page, errs.InvalidFields(invalid...)
+	}
+	return page, nil
+}
+
+func (q ListQuery) fields() []string {
+	var ret []string
+	for _, field := range strings.Split(q.Fields, ",") {
+		if field = strings.TrimSpace(field); field != "" {
+			ret = append(ret, field)
+		}
+	}
+	return ret
+}
+
+/*
+respond links the next page in headers, and encodes facilities in format projected to the fields of the query.
+csv always has all columns of data.csv, so it can be imported back.
+*/
+func (q ListQuery) respond(ctx iris.Context, format ListFormat, facilities []models.Facility, next *services.Page) any {
+	fields := q.fields()
+	if format == ListCSV && len(fields) > 0 {
+		return errs.InvalidFields(errs.FieldError{Field: "fields", Reason: "is not supported by format csv"})
+	}
+	if next != nil {
+		cursor := encodeCursor(next.Offset)
+		u := *ctx.Request().URL
+		values := u.Query()
+		values.Set("cursor", cursor)
+		u.RawQuery = values.Encode()
+		ctx.Header("X-Next-Cursor", cursor)
+		ctx.Header("Link", fmt.Sprintf(`<%s>; rel="next"`, u.RequestURI()))
+	}
+	switch format {
+	case ListGeoJSON:
+		return facilitiesGeoJSON(facilities, fields)
+	case ListCSV:
+		return facilitiesCSV(facilities)
+	case ListNDJSON:
+		return facilitiesNDJSON(facilities, fields)
+	}
+	if len(fields) == 0 {
+		return facilities
+	}
+	ret := make([]map[string]any, len(facilities))
+	for i, facility := range facilities {
+		projected, err := facilityProperties(facility, fields)
+		if err != nil {
+			return err
+		}
+		ret[i] = projected
+	}
+	return ret
+}
+
+// facilityProperties are the json fields of the facility, only the given ones if any
+func facilityProperties(facility models.Facility, fields []string) (map[string]any, error) {
+	bs, err := json.Marshal(facility)
+	if err != nil {
+		return nil, errs.Err(err)
+	}
+	var all map[string]json.RawMessage
+	if err = json.Unmarshal(bs, &all); err != nil {
+		return nil, errs.Err(err)
+	}
+	ret := make(map[string]any, len(all))
+	if len(fields) == 0 {
+		for field, value := range all {
+			ret[field] = value
+		}
+		return ret, nil
+	}
+	for _, field := range fields {
+		if value, ok := all[field]; ok {
+			ret[field] = value
+		}
+	}
+	return ret, nil
+}
+
+// cursors are opaque to clients, they encode the offset of the page
+func encodeCursor(offset int) string {
+	return base64.RawURLEncoding.EncodeToString([]byte("o" + strconv.Itoa(offset)))
+}
+
+func decodeCursor(cursor string) (int, error) {
+	bs, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return 0, err
+	}
+	s, ok := strings.CutPrefix(string(bs), "o")
+	if !ok {
+		return 0, errors.New("unknown cursor")
+	}
+	offset, err := strconv.Atoi(s)
+	if err != nil || offset < 0 {
+		return 0, errors.New("unknown cursor")
+	}
+	return offset, nil
+}
+
+// facilityFields are the json names of facility fields, which fields= selects from
+var facilityFields = func() map[string]bool {
+	ret := make(map[string]bool)
+	typ := reflect.TypeOf(models.Facility{})
+	for i := 0; i < typ.NumField(); i++ {
+		if name, _, _ := strings.Cut(typ.Field(i).Tag.Get("json"), ","); name != "" && name != "-" {
+			ret[name] = true
+		}
+	}
+	return ret
+}()
@@ -0,0 +1,71 @@
+package controllers
+
+import (
+	"encoding/base64"
+	"food-trucks/packages/models"
+	"food-trucks/packages/services"
+	"food-trucks/packages/util/errs"
+	"testing"
+)
+
+func TestListQuery_Page(t *testing.T) {
+	page, err := ListQuery{Limit: 20, Cursor: encodeCursor(40), Fields: "locationID, latitude,,longitude"}.Page()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if page != (services.Page{Offset: 40, Limit: 20}) {
+		t.Fatalf("expect the page of the cursor, got %+v", page)
+	}
+	if page, err = (ListQuery{}).Page(); err != nil || page != (services.Page{}) {
+		t.Fatalf("expect the first page of all, got %+v %v", page, err)
+	}
+
+	for _, cursor := range []string{
+		"not a cursor!",
+		base64.RawURLEncoding.EncodeToString([]byte("x40")),
+		base64.RawURLEncoding.EncodeToString([]byte("o-1")),
+		base64.RawURLEncoding.EncodeToString([]byte("oabc")),
+	} {
+		_, err = ListQuery{Cursor: cursor}.Page()
+		if fields := errs.FieldsOf(err); len(fields) != 1 || fields[0].Field != "cursor" {
+			t.Fatalf("expect cursor %q invalid, got %v", cursor, err)
+		}
+	}
+
+	_, err = ListQuery{Cursor: "bad!", Fields: "locationID,owner,applicant,price"}.Page()
+	want := []errs.FieldError{
+		{Field: "cursor", Reason: "is not a cursor of a list"},
+		{Field: "fields", Reason: `has unknown field "owner"`},
+		{Field: "fields", Reason: `has unknown field "price"`},
+	}
+	fields := errs.FieldsOf(err)
+	if errs.KindOf(err) != errs.KindInvalid || len(fields) != len(want) {
+		t.Fatalf("expect %v, got %v", want, err)
+	}
+	for i := range want {
+		if fields[i] != want[i] {
+			t.Fatalf("expect %v, got %v", want, fields)
+		}
+	}
+}
+
+func TestListQuery_Respond(t *testing.T) {
+	ctx := newContext("/api/facilities?limit=1&fields=applicant", "")
+	q := ListQuery{Limit: 1, Fields: "applicant"}
+	ret := q.respond(ctx, ListJSON, []models.Facility{anzu}, &services.Page{Offset: 1, Limit: 1})
+	projected, ok := ret.([]map[string]any)
+	if !ok || len(projected) != 1 || len(projected[0]) != 1 {
+		t.Fatalf("expect facilities projected to applicant, got %v", ret)
+	}
+	cursor := ctx.ResponseWriter().Header().Get("X-Next-Cursor")
+	if offset, err := decodeCursor(cursor); err != nil || offset != 1 {
+		t.Fatalf("expect the cursor of offset 1, got %q", cursor)
+	}
+	if link := ctx.ResponseWriter().Header().Get("Link"); link != `</api/facilities?cursor=`+cursor+`&fields=applicant&limit=1>; rel="next"` {
+		t.Fatalf("unexpected link %s", link)
+	}
+
+	if err, ok := q.respond(newContext("/", ""), ListCSV, nil, nil).(error); !ok || errs.KindOf(err) != errs.KindInvalid {
+		t.Fatalf("expect fields with csv invalid, got %v", err)
+	}
+}
@@ -0,0 +1,38 @@
+package controllers
+
+import (
+	"food-trucks/packages/services"
+	"food-trucks/packages/util/errs"
+	"github.com/kataras/iris/v12"
+	"github.com/kataras/iris/v12/mvc"
+	"strconv"
+	"strings"
+)
+
+// mvtContentType is the media type of Mapbox Vector Tiles
+const mvtContentType = "application/vnd.mapbox-vector-tile"
+
+type TileCtl struct {
+	C           iris.Context
+	FacilitySvc *services.FacilitySvc
+}
+
+/*
+GetByByBy serves /{z}/{x}/{y}.mvt, facility points of a web mercator tile as a Mapbox Vector Tile
+with layer "facilities", for map libraries to draw every facility at city-wide zoom.
+*/
+func (t TileCtl) GetByByBy(z int, x int, file string) any {
+	name, ok := strings.CutSuffix(file, ".mvt")
+	if !ok {
+		return errs.NotFound("tiles are .mvt")
+	}
+	y, err := strconv.Atoi(name)
+	if err != nil {
+		return errs.NotFound("tiles are {z}/{x}/{y}.mvt")
+	}
+	tile, err := t.FacilitySvc.GetTile(t.C.Request().Context(), z, x, y)
+	if err != nil {
+		return err
+	}
+	return mvc.Response{ContentType: mvtContentType, Content: tile}
+}
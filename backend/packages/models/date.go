@@ -0,0 +1,44 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	// DateTimeLayout is how Approved and ExpirationDate are written in data.csv, e.g. "11/15/2022 12:00:00 AM"
+	DateTimeLayout = "01/02/2006 03:04:05 PM"
+	// DateLayout is how Received is written in data.csv, e.g. "20211105"
+	DateLayout = "20060102"
+)
+
+var dateLayouts = []string{DateTimeLayout, DateLayout, "2006-01-02T15:04:05.000", time.RFC3339, time.DateOnly}
+
+// ParseDate accepts layouts of data.csv and ISO dates, empty string is zero time
+func ParseDate(s string) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return time.Time{}, nil
+	}
+	for _, layout := range dateLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("%q is not a date", s)
+}
+
+func FormatDateTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(DateTimeLayout)
+}
+
+func FormatDate(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(DateLayout)
+}
@@ -1,35 +1,112 @@
 package models
 
+import (
+	"encoding/json"
+	"time"
+)
+
 type Facility struct {
-	LocationID              string  `json:"locationID"`
-	Applicant               string  `json:"applicant"`
-	FacilityType            string  `json:"facilityType"`
-	CNN                     string  `json:"cnn"`
-	LocationDescription     string  `json:"locationDescription"`
-	Address                 string  `json:"address"`
-	BlockLot                string  `json:"blockLot"`
-	Block                   string  `json:"block"`
-	Lot                     string  `json:"lot"`
-	Permit                  string  `json:"permit"`
-	Status                  string  `json:"status"`
-	FoodItems               string  `json:"foodItems"`
-	X                       float64 `json:"x"`
-	Y                       float64 `json:"y"`
-	Latitude                float64 `json:"latitude"`
-	Longitude               float64 `json:"longitude"`
-	Schedule                string  `json:"schedule"`
-	DaysHours               string  `json:"daysHours"`
-	NOISent                 string  `json:"NOISent"`
-	Approved                string  `json:"approved"`
-	Received                string  `json:"received"`
-	PriorPermit             string  `json:"priorPermit"`
-	ExpirationDate          string  `json:"expirationDate"`
-	Location                string  `json:"location"`
-	FirePreventionDistricts string  `json:"firePreventionDistricts"`
-	PoliceDistricts         string  `json:"policeDistricts"`
-	SupervisorDistricts     string  `json:"supervisorDistricts"`
-	ZipCodes                string  `json:"zipCodes"`
-	NeighborhoodsOld        string  `json:"neighborhoodsOld"`
+	LocationID          string       `json:"locationID"`
+	Applicant           string       `json:"applicant"`
+	FacilityType        string       `json:"facilityType"`
+	CNN                 string       `json:"cnn"`
+	LocationDescription string       `json:"locationDescription"`
+	Address             string       `json:"address"`
+	BlockLot            string       `json:"blockLot"`
+	Block               string       `json:"block"`
+	Lot                 string       `json:"lot"`
+	Permit              string       `json:"permit"`
+	Status              PermitStatus `json:"status"`
+	FoodItems           []string     `json:"foodItems"`
+	X                   float64      `json:"x"`
+	Y                   float64      `json:"y"`
+	Latitude            float64      `json:"latitude"`
+	Longitude           float64      `json:"longitude"`
+	Schedule            string       `json:"schedule"`
+	DaysHours           string       `json:"daysHours"`
+	// Hours is parsed from DaysHours, empty if unknown
+	Hours                   WeeklySchedule `json:"hours,omitempty"`
+	NOISent                 string         `json:"NOISent"`
+	Approved                time.Time      `json:"approved"`
+	Received                time.Time      `json:"received"`
+	PriorPermit             string         `json:"priorPermit"`
+	ExpirationDate          time.Time      `json:"expirationDate"`
+	Location                string         `json:"location"`
+	FirePreventionDistricts string         `json:"firePreventionDistricts"`
+	PoliceDistricts         string         `json:"policeDistricts"`
+	SupervisorDistricts     string         `json:"supervisorDistricts"`
+	ZipCodes                string         `json:"zipCodes"`
+	NeighborhoodsOld        string         `json:"neighborhoodsOld"`
+}
+
+// facilityFields has Facility's fields without its json methods
+type facilityFields Facility
+
+/*
+facilityJSON keeps the wire format of the csv era, which the frontend depends on:
+food items are joined by ": ", dates are formatted as in data.csv and zero dates are "".
+Its fields shadow the typed ones of the embedded facilityFields.
+*/
+type facilityJSON struct {
+	*facilityFields
+	FoodItems      json.RawMessage `json:"foodItems"`
+	Approved       string          `json:"approved"`
+	Received       string          `json:"received"`
+	ExpirationDate string          `json:"expirationDate"`
+}
+
+func (f Facility) MarshalJSON() ([]byte, error) {
+	foodItems, err := json.Marshal(JoinFoodItems(f.FoodItems))
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(facilityJSON{
+		facilityFields: (*facilityFields)(&f),
+		FoodItems:      foodItems,
+		Approved:       FormatDateTime(f.Approved),
+		Received:       FormatDate(f.Received),
+		ExpirationDate: FormatDateTime(f.ExpirationDate),
+	})
+}
+
+// UnmarshalJSON accepts food items as a ": " separated string or as an array
+func (f *Facility) UnmarshalJSON(data []byte) error {
+	w := facilityJSON{facilityFields: (*facilityFields)(f)}
+	if err := json.Unmarshal(data, &w); err != nil {
+		return err
+	}
+	var err error
+	if f.FoodItems, err = parseFoodItemsJSON(w.FoodItems); err != nil {
+		return err
+	}
+	if f.Approved, err = ParseDate(w.Approved); err != nil {
+		return err
+	}
+	if f.Received, err = ParseDate(w.Received); err != nil {
+		return err
+	}
+	if f.ExpirationDate, err = ParseDate(w.ExpirationDate); err != nil {
+		return err
+	}
+	return nil
+}
+
+func parseFoodItemsJSON(raw json.RawMessage) ([]string, error) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil, nil
+	}
+	if raw[0] == '[' {
+		var items []string
+		if err := json.Unmarshal(raw, &items); err != nil {
+			return nil, err
+		}
+		return NormalizeFoodItems(items...), nil
+	}
+	var s string
+	if err := json.Unmarshal(raw, &s); err != nil {
+		return nil, err
+	}
+	return ParseFoodItems(s), nil
 }
 
 func GetFacilityLocation(f Facility) (float64, float64) {
@@ -0,0 +1,75 @@
+package models
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFacility_MarshalJSON(t *testing.T) {
+	f := Facility{
+		LocationID:     "1569145",
+		Status:         PermitApproved,
+		FoodItems:      []string{"Coffee", "Vegan Pastries"},
+		Approved:       time.Date(2021, 11, 5, 0, 0, 0, 0, time.UTC),
+		Received:       time.Date(2021, 11, 5, 0, 0, 0, 0, time.UTC),
+		ExpirationDate: time.Date(2022, 11, 15, 0, 0, 0, 0, time.UTC),
+	}
+	bs, err := json.Marshal(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := string(bs)
+	for _, expect := range []string{
+		`"status":"APPROVED"`,
+		`"foodItems":"Coffee: Vegan Pastries"`,
+		`"approved":"11/05/2021 12:00:00 AM"`,
+		`"received":"20211105"`,
+		`"expirationDate":"11/15/2022 12:00:00 AM"`,
+	} {
+		if !strings.Contains(s, expect) {
+			t.Fatalf("expect %s in %s", expect, s)
+		}
+	}
+
+	var back Facility
+	if err := json.Unmarshal(bs, &back); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(f, back) {
+		t.Fatalf("round trip mismatch\n%+v\n%+v", f, back)
+	}
+}
+
+func TestFacility_UnmarshalJSON(t *testing.T) {
+	var f Facility
+	err := json.Unmarshal([]byte(`{"locationID":"1","foodItems":["Tacos ", "tacos", "Burritos"],"approved":"","received":"2021-11-05"}`), &f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(f.FoodItems, []string{"Tacos", "Burritos"}) || !f.Approved.IsZero() || f.Received.Day() != 5 {
+		t.Fatalf("unexpected %+v", f)
+	}
+	if err := json.Unmarshal([]byte(`{"approved":"yesterday"}`), &f); err == nil {
+		t.Fatal("expect error for invalid date")
+	}
+}
+
+func TestParseFoodItems(t *testing.T) {
+	items := ParseFoodItems("Lomo Saltado: Jalea:  chicken special. Soda: Water.; : water")
+	expect := []string{"Lomo Saltado", "Jalea", "chicken special. Soda", "Water"}
+	if !reflect.DeepEqual(items, expect) {
+		t.Fatalf("expect %q, got %q", expect, items)
+	}
+}
+
+func TestParsePermitStatus(t *testing.T) {
+	if s, err := ParsePermitStatus(" expired"); err != nil || s != PermitExpired {
+		t.Fatalf("unexpected %v %v", s, err)
+	}
+	if _, err := ParsePermitStatus("CLOSED"); err == nil {
+		t.Fatal("expect error for unknown status")
+	}
+}
@@ -0,0 +1,37 @@
+package models
+
+import (
+	"strings"
+)
+
+// ParseFoodItems splits food items of data.csv, e.g. "Coffee: Vegan Pastries: Te: Vegan Shakes"
+func ParseFoodItems(s string) []string {
+	return NormalizeFoodItems(strings.FieldsFunc(s, func(r rune) bool {
+		return r == ':' || r == ';'
+	})...)
+}
+
+// NormalizeFoodItems collapses white spaces, trims trailing periods and drops empty or duplicated items
+func NormalizeFoodItems(items ...string) []string {
+	var ret []string
+	seen := make(map[string]bool)
+	for _, item := range items {
+		item = strings.TrimRight(strings.Join(strings.Fields(item), " "), ".")
+		key := FoodItemKey(item)
+		if key == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		ret = append(ret, item)
+	}
+	return ret
+}
+
+// FoodItemKey is the case-insensitive key a food item is indexed by
+func FoodItemKey(item string) string {
+	return strings.ToLower(strings.TrimRight(strings.Join(strings.Fields(item), " "), "."))
+}
+
+func JoinFoodItems(items []string) string {
+	return strings.Join(items, ": ")
+}
@@ -0,0 +1,32 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+)
+
+type PermitStatus string
+
+const (
+	PermitApproved  PermitStatus = "APPROVED"
+	PermitRequested PermitStatus = "REQUESTED"
+	PermitExpired   PermitStatus = "EXPIRED"
+	PermitSuspend   PermitStatus = "SUSPEND"
+	PermitIssued    PermitStatus = "ISSUED"
+)
+
+var PermitStatuses = []PermitStatus{PermitApproved, PermitRequested, PermitExpired, PermitSuspend, PermitIssued}
+
+// ParsePermitStatus is case-insensitive, empty string is a valid unknown status
+func ParsePermitStatus(s string) (PermitStatus, error) {
+	s = strings.ToUpper(strings.TrimSpace(s))
+	if s == "" {
+		return "", nil
+	}
+	for _, status := range PermitStatuses {
+		if string(status) == s {
+			return status, nil
+		}
+	}
+	return "", fmt.Errorf("unknown permit status %q", s)
+}
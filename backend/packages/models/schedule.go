@@ -0,0 +1,230 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	_ "time/tzdata" // the schedule time zone must load on hosts without zoneinfo
+)
+
+// ScheduleLocation is the time zone dayshours are written in, all facilities are in San Francisco
+var ScheduleLocation = mustLoadLocation("America/Los_Angeles")
+
+func mustLoadLocation(name string) *time.Location {
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		panic(err)
+	}
+	return loc
+}
+
+const minutesPerDay = 24 * 60
+
+var weekdays = map[string]time.Weekday{
+	"su": time.Sunday, "mo": time.Monday, "tu": time.Tuesday, "we": time.Wednesday,
+	"th": time.Thursday, "fr": time.Friday, "sa": time.Saturday,
+}
+
+var weekdayNames = []string{"Su", "Mo", "Tu", "We", "Th", "Fr", "Sa"}
+
+// OpenHours is an opening time range of a weekday, Close is past 24h if the range ends after midnight
+type OpenHours struct {
+	Day time.Weekday
+	// Open is minutes since midnight of Day
+	Open int
+	// Close is minutes since midnight of Day, greater than Open
+	Close int
+}
+
+type openHoursJSON struct {
+	Day   string `json:"day"`
+	Open  string `json:"open"`
+	Close string `json:"close"`
+}
+
+// MarshalJSON writes e.g. {"day":"Fr","open":"20:00","close":"02:00"}, a close not after open is on the next day
+func (h OpenHours) MarshalJSON() ([]byte, error) {
+	return json.Marshal(openHoursJSON{
+		Day:   weekdayNames[h.Day],
+		Open:  formatMinutes(h.Open),
+		Close: formatMinutes(h.Close),
+	})
+}
+
+func (h *OpenHours) UnmarshalJSON(data []byte) error {
+	var v openHoursJSON
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	day, ok := weekdays[strings.ToLower(v.Day)]
+	if !ok {
+		return fmt.Errorf("%q is not a weekday", v.Day)
+	}
+	open, err := parseMinutes(v.Open)
+	if err != nil {
+		return err
+	}
+	closing, err := parseMinutes(v.Close)
+	if err != nil {
+		return err
+	}
+	*h = newOpenHours(day, open, closing)
+	return nil
+}
+
+func newOpenHours(day time.Weekday, open int, closing int) OpenHours {
+	if closing <= open {
+		closing += minutesPerDay
+	}
+	return OpenHours{Day: day, Open: open, Close: closing}
+}
+
+func formatMinutes(m int) string {
+	m %= minutesPerDay
+	return fmt.Sprintf("%02d:%02d", m/60, m%60)
+}
+
+func parseMinutes(s string) (int, error) {
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, fmt.Errorf("%q is not a time of day", s)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// WeeklySchedule is the parsed dayshours of a facility, sorted by day and open time
+type WeeklySchedule []OpenHours
+
+// e.g. 7AM, 12PM, 7:30PM
+var clockPattern = regexp.MustCompile(`^(\d{1,2})(?::(\d{2}))?([AP]M)$`)
+
+/*
+ParseWeeklySchedule parses dayshours of data.csv, groups are separated by ";",
+each group is days and time ranges separated by ":", e.g. "Sa-Su:11AM-10PM;Mo/We/Fr:7AM-8AM/10AM-11AM".
+Days are a range "Mo-Fr" or a list "Mo/We", a range ending before it starts goes past midnight, e.g. "8PM-2AM".
+Empty string is an empty schedule.
+*/
+func ParseWeeklySchedule(s string) (WeeklySchedule, error) {
+	var ret WeeklySchedule
+	seen := make(map[OpenHours]bool)
+	for _, group := range strings.Split(s, ";") {
+		group = strings.TrimSpace(group)
+		if group == "" {
+			continue
+		}
+		days, ranges, ok := strings.Cut(group, ":")
+		if !ok {
+			return nil, fmt.Errorf("%q misses days or hours", group)
+		}
+		weekdays, err := parseWeekdays(days)
+		if err != nil {
+			return nil, err
+		}
+		for _, r := range strings.Split(ranges, "/") {
+			from, to, ok := strings.Cut(strings.TrimSpace(r), "-")
+			if !ok {
+				return nil, fmt.Errorf("%q is not a time range", r)
+			}
+			open, err := parseClock(from)
+			if err != nil {
+				return nil, err
+			}
+			closing, err := parseClock(to)
+			if err != nil {
+				return nil, err
+			}
+			for _, day := range weekdays {
+				if h := newOpenHours(day, open, closing); !seen[h] {
+					seen[h] = true
+					ret = append(ret, h)
+				}
+			}
+		}
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		if ret[i].Day != ret[j].Day {
+			return ret[i].Day < ret[j].Day
+		}
+		return ret[i].Open < ret[j].Open
+	})
+	return ret, nil
+}
+
+func parseWeekdays(s string) ([]time.Weekday, error) {
+	parseDay := func(name string) (time.Weekday, error) {
+		day, ok := weekdays[strings.ToLower(strings.TrimSpace(name))]
+		if !ok {
+			return 0, fmt.Errorf("%q is not a weekday", name)
+		}
+		return day, nil
+	}
+	if from, to, ok := strings.Cut(s, "-"); ok {
+		first, err := parseDay(from)
+		if err != nil {
+			return nil, err
+		}
+		last, err := parseDay(to)
+		if err != nil {
+			return nil, err
+		}
+		// a range may wrap around the week, e.g. Fr-Mo
+		var ret []time.Weekday
+		for day := first; ; day = (day + 1) % 7 {
+			ret = append(ret, day)
+			if day == last {
+				return ret, nil
+			}
+		}
+	}
+	var ret []time.Weekday
+	for _, name := range strings.Split(s, "/") {
+		day, err := parseDay(name)
+		if err != nil {
+			return nil, err
+		}
+		ret = append(ret, day)
+	}
+	return ret, nil
+}
+
+// parseClock returns minutes since midnight, 12AM is midnight and 12PM is noon
+func parseClock(s string) (int, error) {
+	m := clockPattern.FindStringSubmatch(strings.ToUpper(strings.TrimSpace(s)))
+	if m == nil {
+		return 0, fmt.Errorf("%q is not a time", s)
+	}
+	hour, _ := strconv.Atoi(m[1])
+	minute := 0
+	if m[2] != "" {
+		minute, _ = strconv.Atoi(m[2])
+	}
+	if hour < 1 || hour > 12 || minute > 59 {
+		return 0, fmt.Errorf("%q is not a time", s)
+	}
+	hour %= 12
+	if m[3] == "PM" {
+		hour += 12
+	}
+	return hour*60 + minute, nil
+}
+
+// IsOpen tells if t falls in a range of the schedule, an empty (unknown) schedule is never open
+func (s WeeklySchedule) IsOpen(t time.Time) bool {
+	t = t.In(ScheduleLocation)
+	day, minute := t.Weekday(), t.Hour()*60+t.Minute()
+	yesterday := (day + 6) % 7
+	for _, h := range s {
+		if h.Day == day && minute >= h.Open && minute < h.Close {
+			return true
+		}
+		// the range of yesterday goes past midnight
+		if h.Day == yesterday && minute+minutesPerDay < h.Close {
+			return true
+		}
+	}
+	return false
+}
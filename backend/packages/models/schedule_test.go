@@ -0,0 +1,87 @@
+package models
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestParseWeeklySchedule(t *testing.T) {
+	cases := []struct {
+		dayshours string
+		expect    int
+	}{
+		{"", 0},
+		{"Mo-We:7AM-7PM", 3},
+		{"Mo-Fr:7AM-8AM/10AM-11AM/12PM-1PM", 15},
+		{"Mo/Mo/Mo/Mo/Mo:9AM-10AM/10AM-11AM", 2},
+		{"Sa-Su:11AM-10PM;Mo-Fr:6PM-8PM", 7},
+		{"Su/Fr/Sa:8PM-2AM", 3},
+		{"Fr-Mo:9:30AM-1PM", 4},
+	}
+	for _, c := range cases {
+		schedule, err := ParseWeeklySchedule(c.dayshours)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(schedule) != c.expect {
+			t.Fatalf("%q expect %d ranges, got %v", c.dayshours, c.expect, schedule)
+		}
+	}
+
+	for _, invalid := range []string{"Mo-Fr", "Xx:7AM-8AM", "Mo:7AM", "Mo:13PM-1AM"} {
+		if _, err := ParseWeeklySchedule(invalid); err == nil {
+			t.Fatalf("expect %q to be invalid", invalid)
+		}
+	}
+}
+
+func TestWeeklySchedule_IsOpen(t *testing.T) {
+	schedule, err := ParseWeeklySchedule("Fr:8PM-2AM;Mo:12PM-12AM")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 2022-01-28 is a Friday
+	at := func(day, hour, minute int) time.Time {
+		return time.Date(2022, 1, day, hour, minute, 0, 0, ScheduleLocation)
+	}
+	cases := []struct {
+		t      time.Time
+		expect bool
+	}{
+		{at(28, 19, 59), false},
+		{at(28, 20, 0), true},
+		{at(29, 1, 59), true},
+		{at(29, 2, 0), false},
+		{at(31, 23, 59), true},
+		{at(31, 11, 0), false},
+		{at(29, 4, 0).UTC(), false},
+		{at(28, 21, 0).UTC(), true},
+	}
+	for i, c := range cases {
+		if got := schedule.IsOpen(c.t); got != c.expect {
+			t.Fatalf("case %d %v expect %v, got %v", i, c.t, c.expect, got)
+		}
+	}
+}
+
+func TestOpenHours_JSON(t *testing.T) {
+	schedule, err := ParseWeeklySchedule("Fr:8PM-2AM")
+	if err != nil {
+		t.Fatal(err)
+	}
+	bs, err := json.Marshal(schedule)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(bs) != `[{"day":"Fr","open":"20:00","close":"02:00"}]` {
+		t.Fatalf("unexpected json %s", bs)
+	}
+	var decoded WeeklySchedule
+	if err := json.Unmarshal(bs, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if len(decoded) != 1 || decoded[0] != schedule[0] {
+		t.Fatalf("expect %v, got %v", schedule, decoded)
+	}
+}
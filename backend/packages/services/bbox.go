@@ -0,0 +1,31 @@
+package services
+
+import (
+	"fmt"
+)
+
+// BBox is a lat/lon bounding box, it doesn't cross the anti meridian, so MinLon <= MaxLon
+type BBox struct {
+	MinLat float64 `json:"minLat"`
+	MinLon float64 `json:"minLon"`
+	MaxLat float64 `json:"maxLat"`
+	MaxLon float64 `json:"maxLon"`
+}
+
+func (b BBox) Validate() error {
+	switch {
+	case b.MinLat < -90 || b.MaxLat > 90:
+		return fmt.Errorf("%w: latitude should be in [-90, 90]", ErrInvalidArgument)
+	case b.MinLon < -180 || b.MaxLon > 180:
+		return fmt.Errorf("%w: longitude should be in [-180, 180]", ErrInvalidArgument)
+	case b.MinLat > b.MaxLat:
+		return fmt.Errorf("%w: minLat %v is greater than maxLat %v", ErrInvalidArgument, b.MinLat, b.MaxLat)
+	case b.MinLon > b.MaxLon:
+		return fmt.Errorf("%w: minLon %v is greater than maxLon %v", ErrInvalidArgument, b.MinLon, b.MaxLon)
+	}
+	return nil
+}
+
+func (b BBox) Contains(lat, lon float64) bool {
+	return lat >= b.MinLat && lat <= b.MaxLat && lon >= b.MinLon && lon <= b.MaxLon
+}
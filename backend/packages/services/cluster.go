@@ -0,0 +1,92 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"food-trucks/packages/models"
+	"math"
+	"sort"
+)
+
+const (
+	// clusterCellPixels is the side of a clustering cell on screen, markers closer than it are merged
+	clusterCellPixels = 64
+	// clusterMaxZoom is the last zoom level which clusters, deeper levels return individual facilities
+	clusterMaxZoom = 16
+	// clusterSampleSize is how many applicants are listed for a cluster
+	clusterSampleSize = 3
+)
+
+type Cluster struct {
+	Latitude   float64  `json:"latitude"`
+	Longitude  float64  `json:"longitude"`
+	Count      int      `json:"count"`
+	Applicants []string `json:"applicants"`
+}
+
+// ClusterResult contains clusters for crowded cells and facilities for cells with a single facility
+type ClusterResult struct {
+	Clusters   []Cluster         `json:"clusters"`
+	Facilities []models.Facility `json:"facilities"`
+}
+
+/*
+GetClusters groups facilities inside box by a grid of clusterCellPixels on screen at zoom,
+a cluster's location is the centroid of its facilities.
+Above clusterMaxZoom every facility is returned individually.
+*/
+func (t *FacilitySvc) GetClusters(ctx context.Context, box BBox, zoom int, filter Filter) (*ClusterResult, error) {
+	if zoom < 0 || zoom > MaxZoom {
+		return nil, fmt.Errorf("%w: zoom should be in [0, %d]", ErrInvalidArgument, MaxZoom)
+	}
+	facilities, _, err := t.GetByBox(ctx, box, filter, Page{})
+	if err != nil {
+		return nil, err
+	}
+	ret := &ClusterResult{Clusters: []Cluster{}, Facilities: []models.Facility{}}
+	if zoom > clusterMaxZoom {
+		ret.Facilities = facilities
+		return ret, nil
+	}
+
+	type cell struct{ X, Y int }
+	var cells []cell
+	groups := make(map[cell][]models.Facility)
+	for _, facility := range facilities {
+		x, y := mercatorPixel(facility.Latitude, facility.Longitude, zoom)
+		c := cell{X: int(math.Floor(x / clusterCellPixels)), Y: int(math.Floor(y / clusterCellPixels))}
+		if _, ok := groups[c]; !ok {
+			cells = append(cells, c)
+		}
+		groups[c] = append(groups[c], facility)
+	}
+
+	for _, c := range cells {
+		group := groups[c]
+		if len(group) == 1 {
+			ret.Facilities = append(ret.Facilities, group[0])
+			continue
+		}
+		ret.Clusters = append(ret.Clusters, newCluster(group))
+	}
+	sort.SliceStable(ret.Clusters, func(i, j int) bool {
+		return ret.Clusters[i].Count > ret.Clusters[j].Count
+	})
+	return ret, nil
+}
+
+func newCluster(facilities []models.Facility) Cluster {
+	cluster := Cluster{Count: len(facilities), Applicants: []string{}}
+	seen := make(map[string]bool)
+	for _, facility := range facilities {
+		cluster.Latitude += facility.Latitude
+		cluster.Longitude += facility.Longitude
+		if len(cluster.Applicants) < clusterSampleSize && facility.Applicant != "" && !seen[facility.Applicant] {
+			seen[facility.Applicant] = true
+			cluster.Applicants = append(cluster.Applicants, facility.Applicant)
+		}
+	}
+	cluster.Latitude /= float64(len(facilities))
+	cluster.Longitude /= float64(len(facilities))
+	return cluster
+}
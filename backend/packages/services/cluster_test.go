@@ -0,0 +1,60 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestFacilitySvc_GetClusters(t *testing.T) {
+	ctx := context.Background()
+	svc := mustInit()
+	box := BBox{MinLat: 37.70, MinLon: -122.52, MaxLat: 37.82, MaxLon: -122.35}
+	all, _, err := svc.GetByBox(ctx, box, Filter{}, Page{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, zoom := range []int{10, 13, 16} {
+		res, err := svc.GetClusters(ctx, box, zoom, Filter{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		count := len(res.Facilities)
+		for _, cluster := range res.Clusters {
+			if cluster.Count < 2 || len(cluster.Applicants) == 0 || !box.Contains(cluster.Latitude, cluster.Longitude) {
+				t.Fatalf("unexpected cluster %+v", cluster)
+			}
+			count += cluster.Count
+		}
+		if count != len(all) {
+			t.Fatalf("zoom %d: expect clusters to cover %d facilities, got %d", zoom, len(all), count)
+		}
+		if zoom == 10 && len(res.Clusters) > 20 {
+			t.Fatalf("expect few clusters for the whole city, got %d", len(res.Clusters))
+		}
+	}
+
+	res, err := svc.GetClusters(ctx, box, 17, Filter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Clusters) != 0 || len(res.Facilities) != len(all) {
+		t.Fatalf("expect individual facilities at zoom 17, got %d clusters", len(res.Clusters))
+	}
+
+	if _, err := svc.GetClusters(ctx, box, 30, Filter{}); !errors.Is(err, ErrInvalidArgument) {
+		t.Fatalf("expect invalid argument, got %v", err)
+	}
+}
+
+func TestMercatorPixel(t *testing.T) {
+	x, y := mercatorPixel(0, 0, 0)
+	if x != 128 || y != 128 {
+		t.Fatalf("expect center of the world at (128, 128), got (%v, %v)", x, y)
+	}
+	x, y = mercatorPixel(maxMercatorLat, -180, 1)
+	if x != 0 || y > 1e-6 {
+		t.Fatalf("expect top left at (0, 0), got (%v, %v)", x, y)
+	}
+}
@@ -0,0 +1,12 @@
+package services
+
+import "food-trucks/packages/util/errs"
+
+var (
+	// ErrInvalidArgument is returned for malformed query input, the web app maps it to 400
+	ErrInvalidArgument = errs.Invalid("invalid argument")
+	// ErrNotFound is returned when the requested facility doesn't exist, the web app maps it to 404
+	ErrNotFound = errs.NotFound("not found")
+	// ErrConflict is returned when creating a facility whose id exists, the web app maps it to 409
+	ErrConflict = errs.Conflict("conflict")
+)
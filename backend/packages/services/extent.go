@@ -0,0 +1,120 @@
+package services
+
+import (
+	"food-trucks/packages/models"
+	"math"
+	"sort"
+)
+
+const (
+	// extentTrim is the share of latitudes and longitudes dropped at each end, so a few misplaced facilities don't widen the box
+	extentTrim = 0.01
+	// extentViewportPixels is the side of the smallest map the recommended zoom fits the box into
+	extentViewportPixels = 512
+	// extentPointZoom is the zoom recommended when all facilities are at one point, deeper shows a few streets only
+	extentPointZoom = 16
+)
+
+// Extent is where facilities are for a map to open on, all fields but Count are empty if there is no facility
+type Extent struct {
+	Count int `json:"count"`
+	// BBox bounds facilities except outliers
+	BBox *BBox `json:"bbox"`
+	// Outliers is how many facilities are outside BBox
+	Outliers int `json:"outliers"`
+	// Center is the median latitude and longitude, unlike the mean outliers barely move it
+	Center *Location `json:"center"`
+	// Zoom is the deepest web mercator zoom BBox fits in a square map of extentViewportPixels
+	Zoom int `json:"zoom"`
+}
+
+// GetExtent is the extent of all facilities, recomputed by every sync and edit
+func (t *FacilitySvc) GetExtent() Extent {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.extent
+}
+
+// GetCenter is the median location of all facilities, zero if there is none
+func (t *FacilitySvc) GetCenter() Location {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	if t.extent.Center == nil {
+		return Location{}
+	}
+	return *t.extent.Center
+}
+
+// setLocations should be called with mu locked
+func (t *FacilitySvc) setLocations(facilities []models.Facility) {
+	t.locations = make(map[string]Location, len(facilities))
+	for _, facility := range facilities {
+		t.locations[facility.LocationID] = Location{Lat: facility.Latitude, Lon: facility.Longitude}
+	}
+	t.extent = newExtent(t.locations)
+}
+
+// moveLocation updates the extent for one facility written, old or facility is nil if created or deleted.
+// It should be called with mu locked
+func (t *FacilitySvc) moveLocation(old *models.Facility, facility *models.Facility) {
+	if t.locations == nil {
+		t.locations = make(map[string]Location)
+	}
+	if old != nil {
+		delete(t.locations, old.LocationID)
+	}
+	if facility != nil {
+		t.locations[facility.LocationID] = Location{Lat: facility.Latitude, Lon: facility.Longitude}
+	}
+	t.extent = newExtent(t.locations)
+}
+
+func newExtent(locations map[string]Location) Extent {
+	n := len(locations)
+	if n == 0 {
+		return Extent{}
+	}
+	lats, lons := make([]float64, 0, n), make([]float64, 0, n)
+	for _, location := range locations {
+		lats, lons = append(lats, location.Lat), append(lons, location.Lon)
+	}
+	sort.Float64s(lats)
+	sort.Float64s(lons)
+
+	low := int(extentTrim * float64(n-1))
+	high := n - 1 - low
+	box := BBox{MinLat: lats[low], MinLon: lons[low], MaxLat: lats[high], MaxLon: lons[high]}
+	extent := Extent{
+		Count:  n,
+		BBox:   &box,
+		Center: &Location{Lat: median(lats), Lon: median(lons)},
+		Zoom:   fitZoom(box),
+	}
+	for _, location := range locations {
+		if !box.Contains(location.Lat, location.Lon) {
+			extent.Outliers++
+		}
+	}
+	return extent
+}
+
+// median of sorted values, the mean of the middle two if there are even values
+func median(sorted []float64) float64 {
+	n := len(sorted)
+	if n%2 == 1 {
+		return sorted[n/2]
+	}
+	return (sorted[n/2-1] + sorted[n/2]) / 2
+}
+
+// fitZoom is the deepest zoom box fits in extentViewportPixels, at most extentPointZoom
+func fitZoom(box BBox) int {
+	minX, minY := mercatorPixel(box.MaxLat, box.MinLon, 0)
+	maxX, maxY := mercatorPixel(box.MinLat, box.MaxLon, 0)
+	size := math.Max(maxX-minX, maxY-minY)
+	if size <= 0 {
+		return extentPointZoom
+	}
+	zoom := int(math.Floor(math.Log2(extentViewportPixels / size)))
+	return max(0, min(zoom, extentPointZoom))
+}
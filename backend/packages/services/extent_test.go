@@ -0,0 +1,68 @@
+package services
+
+import (
+	"context"
+	"food-trucks/packages/models"
+	"strconv"
+	"testing"
+)
+
+func TestNewExtent_Empty(t *testing.T) {
+	extent := newTestSvc().GetExtent()
+	if extent.Count != 0 || extent.BBox != nil || extent.Center != nil || extent.Zoom != 0 {
+		t.Fatalf("expect an empty extent, got %+v", extent)
+	}
+	if center := newTestSvc().GetCenter(); center != (Location{}) {
+		t.Fatalf("expect zero center, got %v", center)
+	}
+}
+
+func TestNewExtent_Outliers(t *testing.T) {
+	locations := make(map[string]Location)
+	for i := 0; i < 200; i++ {
+		locations[strconv.Itoa(i)] = Location{Lat: 37.7 + float64(i)*0.0005, Lon: -122.45 + float64(i)*0.0005}
+	}
+	locations["misplaced"] = Location{Lat: 10, Lon: 10}
+	extent := newExtent(locations)
+	// 1% at each end is 2 of 201
+	if extent.Count != 201 || extent.Outliers != 5 || extent.BBox.Contains(10, 10) {
+		t.Fatalf("expect the misplaced and the 4 facilities at the ends outside, got %+v", extent)
+	}
+	if extent.BBox.MaxLat > 37.8 || extent.BBox.MaxLon > -122.35 || extent.BBox.MinLat < 37.7 {
+		t.Fatalf("expect the box around the city, got %+v", extent.BBox)
+	}
+	if extent.Center.Lat < 37.74 || extent.Center.Lat > 37.76 {
+		t.Fatalf("expect the median center in the city, got %+v", extent.Center)
+	}
+	if extent.Zoom != 12 {
+		t.Fatalf("expect zoom 12 for a city, got %d", extent.Zoom)
+	}
+
+	extent = newExtent(map[string]Location{"1": {Lat: 37.7955, Lon: -122.3937}})
+	if extent.Zoom != extentPointZoom || *extent.Center != (Location{Lat: 37.7955, Lon: -122.3937}) {
+		t.Fatalf("unexpected extent of a point %+v", extent)
+	}
+}
+
+func TestFacilitySvc_GetExtent(t *testing.T) {
+	ctx := context.Background()
+	svc := mustInit()
+	extent := svc.GetExtent()
+	if extent.Count == 0 || extent.Zoom < 10 || extent.Zoom > 13 || !extent.BBox.Contains(extent.Center.Lat, extent.Center.Lon) {
+		t.Fatalf("expect the extent of san francisco, got %+v %+v", extent, extent.BBox)
+	}
+
+	if _, err := svc.CreateFacility(ctx, models.Facility{LocationID: "far", Latitude: 40.7, Longitude: -74}); err != nil {
+		t.Fatal(err)
+	}
+	moved := svc.GetExtent()
+	if moved.Count != extent.Count+1 || moved.Outliers != extent.Outliers+1 || *moved.BBox != *extent.BBox {
+		t.Fatalf("expect a far facility to be an outlier, got %+v", moved)
+	}
+	if err := svc.DeleteFacility(ctx, "far"); err != nil {
+		t.Fatal(err)
+	}
+	if moved = svc.GetExtent(); moved.Count != extent.Count || *moved.Center != *extent.Center {
+		t.Fatalf("expect the extent back after delete, got %+v", moved)
+	}
+}
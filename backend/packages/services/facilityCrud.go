@@ -0,0 +1,217 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"food-trucks/packages/models"
+	"food-trucks/packages/util/errs"
+	"strings"
+)
+
+/*
+Facilities written one at a time update every store and the in process state, like a sync does.
+The dataset source stays the source of truth: the applied version is cleared, so the next sync
+compares facilities with the source and reverts edits it doesn't have.
+*/
+
+// CreateFacility stores a new facility, it fails with ErrConflict if the LocationID exists
+func (t *FacilitySvc) CreateFacility(ctx context.Context, facility models.Facility) (models.Facility, error) {
+	if err := prepareFacility(&facility); err != nil {
+		return facility, err
+	}
+	t.syncMu.Lock()
+	defer t.syncMu.Unlock()
+	old, err := t.getFacility(ctx, facility.LocationID)
+	if err != nil {
+		return facility, err
+	}
+	if old != nil {
+		return facility, fmt.Errorf("%w: facility %s exists", ErrConflict, facility.LocationID)
+	}
+	return facility, t.write(ctx, nil, &facility)
+}
+
+// UpdateFacility replaces the facility of id, the LocationID of facility should be id or empty
+func (t *FacilitySvc) UpdateFacility(ctx context.Context, id string, facility models.Facility) (models.Facility, error) {
+	if strings.TrimSpace(facility.LocationID) == "" {
+		facility.LocationID = id
+	}
+	if err := prepareFacility(&facility); err != nil {
+		return facility, err
+	}
+	if facility.LocationID != id {
+		return facility, fmt.Errorf("%w: locationID %s should be %s", ErrInvalidArgument, facility.LocationID, id)
+	}
+	t.syncMu.Lock()
+	defer t.syncMu.Unlock()
+	old, err := t.getFacility(ctx, id)
+	if err != nil {
+		return facility, err
+	}
+	if old == nil {
+		return facility, fmt.Errorf("%w: facility %s", ErrNotFound, id)
+	}
+	return facility, t.write(ctx, old, &facility)
+}
+
+/*
+PatchFacility replaces the fields in patch, a json object keyed as the api returns facilities.
+hours are parsed again from a patched daysHours unless patched too.
+*/
+func (t *FacilitySvc) PatchFacility(ctx context.Context, id string, patch []byte) (models.Facility, error) {
+	var facility models.Facility
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(patch, &fields); err != nil {
+		return facility, fmt.Errorf("%w: %v", ErrInvalidArgument, err)
+	}
+	t.syncMu.Lock()
+	defer t.syncMu.Unlock()
+	old, err := t.getFacility(ctx, id)
+	if err != nil {
+		return facility, err
+	}
+	if old == nil {
+		return facility, fmt.Errorf("%w: facility %s", ErrNotFound, id)
+	}
+
+	bs, err := json.Marshal(old)
+	if err != nil {
+		return facility, errs.Err(err)
+	}
+	var merged map[string]json.RawMessage
+	if err = json.Unmarshal(bs, &merged); err != nil {
+		return facility, errs.Err(err)
+	}
+	for name, value := range fields {
+		merged[name] = value
+	}
+	if _, ok := fields["daysHours"]; ok {
+		if _, ok = fields["hours"]; !ok {
+			delete(merged, "hours")
+		}
+	}
+	if bs, err = json.Marshal(merged); err != nil {
+		return facility, errs.Err(err)
+	}
+	if err = json.Unmarshal(bs, &facility); err != nil {
+		return facility, fmt.Errorf("%w: %v", ErrInvalidArgument, err)
+	}
+	if err = prepareFacility(&facility); err != nil {
+		return facility, err
+	}
+	if facility.LocationID != id {
+		return facility, fmt.Errorf("%w: locationID %s should be %s", ErrInvalidArgument, facility.LocationID, id)
+	}
+	return facility, t.write(ctx, old, &facility)
+}
+
+// DeleteFacility removes the facility of id from every store, it fails with ErrNotFound if there is none
+func (t *FacilitySvc) DeleteFacility(ctx context.Context, id string) error {
+	t.syncMu.Lock()
+	defer t.syncMu.Unlock()
+	old, err := t.getFacility(ctx, id)
+	if err != nil {
+		return err
+	}
+	if old == nil {
+		return fmt.Errorf("%w: facility %s", ErrNotFound, id)
+	}
+	return t.write(ctx, old, nil)
+}
+
+// getFacility returns nil if the facility doesn't exist
+func (t *FacilitySvc) getFacility(ctx context.Context, id string) (*models.Facility, error) {
+	facilities, err := t.FacilityStore.Get(ctx, []string{id})
+	if err != nil {
+		return nil, errs.Err(err)
+	}
+	// a store may return zero values for missing keys
+	if len(facilities) == 0 || facilities[0].LocationID == "" {
+		return nil, nil
+	}
+	return &facilities[0], nil
+}
+
+// prepareFacility normalizes a facility from a request as ReadJSON does, but rejects it instead of skipping
+func prepareFacility(facility *models.Facility) error {
+	if issues := normalizeFacility(facility); len(issues) > 0 {
+		return fmt.Errorf("%w: %s, %s", ErrInvalidArgument, issues[0].Column, issues[0].Reason)
+	}
+	if column, reason := checkFacility(*facility); reason != "" {
+		return fmt.Errorf("%w: %s, %s", ErrInvalidArgument, column, reason)
+	}
+	return nil
+}
+
+/*
+write replaces old with facility in the stores, old is nil if created and facility is nil if deleted.
+It should be called with syncMu locked.
+*/
+func (t *FacilitySvc) write(ctx context.Context, old *models.Facility, facility *models.Facility) error {
+	var id string
+	var oldItems, items []string
+	if old != nil {
+		id, oldItems = old.LocationID, old.FoodItems
+	}
+	if facility != nil {
+		id, items = facility.LocationID, facility.FoodItems
+	}
+
+	if err := t.removeFoodItems(ctx, id, oldItems, items); err != nil {
+		return errs.Errf("failed to remove food items, %w", err)
+	}
+	if facility != nil {
+		facilities := []models.Facility{*facility}
+		if err := t.cacheFacilities(ctx, facilities); err != nil {
+			return errs.Err(err)
+		}
+		if err := t.cacheFoodItems(ctx, facilities); err != nil {
+			return err
+		}
+		// adding an existing member moves its point
+		if err := t.cacheLocations(ctx, facilities); err != nil {
+			return err
+		}
+	} else {
+		if err := t.GeoFacilityStore.Del(ctx, id); err != nil {
+			return errs.Err(err)
+		}
+		if err := t.FacilityStore.Del(ctx, id); err != nil {
+			return errs.Err(err)
+		}
+	}
+
+	t.mu.Lock()
+	t.foodItems.Remove(oldItems...)
+	if facility != nil {
+		t.searchIndex.Add(id, items...)
+		t.foodItems.Add(items...)
+	} else {
+		t.searchIndex.Remove(id)
+	}
+	t.moveLocation(old, facility)
+	t.mu.Unlock()
+
+	return t.writeDatasetState(ctx, id, facility)
+}
+
+// writeDatasetState records the hash of a facility written, facility is nil if deleted
+func (t *FacilitySvc) writeDatasetState(ctx context.Context, id string, facility *models.Facility) error {
+	state, err := t.getDatasetState(ctx)
+	if err != nil {
+		return errs.Errf("failed to get dataset state, %w", err)
+	}
+	if state.Hashes == nil {
+		state.Hashes = make(map[string]string)
+	}
+	if facility == nil {
+		delete(state.Hashes, id)
+	} else if state.Hashes[id], err = hashFacility(*facility); err != nil {
+		return errs.Err(err)
+	}
+	state.Name = facilityDataset
+	state.Version = ""
+	t.setRevision(state.Hashes)
+	return t.DatasetStore.Set(ctx, []DatasetState{state})
+}
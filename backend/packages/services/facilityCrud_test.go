@@ -0,0 +1,92 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"food-trucks/packages/models"
+	"math"
+	"path/filepath"
+	"testing"
+)
+
+func TestFacilitySvc_Crud(t *testing.T) {
+	ctx := context.Background()
+	p := filepath.Join(t.TempDir(), "data.csv")
+	svc := newTestSvc()
+	writeCSV(t, p, `1,Taqueria,Tacos: Burritos,37.7955,-122.3937
+`)
+	if _, err := svc.Sync(p); err != nil {
+		t.Fatal(err)
+	}
+
+	created, err := svc.CreateFacility(ctx, models.Facility{
+		LocationID: " 2 ", FoodItems: []string{"Pizza"}, Latitude: 37.80, Longitude: -122.40, DaysHours: "Mo-Fr:9AM-5PM",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if created.LocationID != "2" || len(created.Hours) != 5 {
+		t.Fatalf("expect created facility normalized, got %v", created)
+	}
+	if _, err = svc.CreateFacility(ctx, created); !errors.Is(err, ErrConflict) {
+		t.Fatalf("expect conflict, got %v", err)
+	}
+	if center := svc.GetCenter(); math.Abs(center.Lat-(37.7955+37.80)/2) > 1e-9 {
+		t.Fatalf("expect center to include created facility, got %v", center)
+	}
+
+	patched, err := svc.PatchFacility(ctx, "2", []byte(`{"foodItems":["Burgers"],"latitude":37.81,"daysHours":""}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if patched.Longitude != -122.40 || len(patched.Hours) != 0 {
+		t.Fatalf("expect unpatched fields kept and hours parsed again, got %v", patched)
+	}
+	if items, _, _ := svc.GetByItem(ctx, "Pizza", Filter{}, Page{}); len(items) != 0 {
+		t.Fatalf("expect old food item removed, got %v", items)
+	}
+	if items, _, _ := svc.GetByItem(ctx, "Burgers", Filter{}, Page{}); len(items) != 1 {
+		t.Fatalf("expect new food item added, got %v", items)
+	}
+	if nearest, _ := svc.GetNearest(ctx, 37.81, -122.40, 1, 0, Filter{}); len(nearest) != 1 || nearest[0].Distance > 1 {
+		t.Fatalf("expect geo point moved, got %v", nearest)
+	}
+	if results, _ := svc.Search(ctx, "pizza", 0, Filter{}); len(results) != 0 {
+		t.Fatalf("expect search index updated, got %v", results)
+	}
+
+	if _, err = svc.UpdateFacility(ctx, "2", models.Facility{LocationID: "3", Latitude: 37.80, Longitude: -122.40}); !errors.Is(err, ErrInvalidArgument) {
+		t.Fatalf("expect mismatched id to be invalid, got %v", err)
+	}
+	if _, err = svc.UpdateFacility(ctx, "2", models.Facility{Latitude: 37.80, Longitude: -122.40, Status: "bogus"}); !errors.Is(err, ErrInvalidArgument) {
+		t.Fatalf("expect unknown status to be invalid, got %v", err)
+	}
+	if _, err = svc.UpdateFacility(ctx, "3", models.Facility{Latitude: 37.80, Longitude: -122.40}); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expect update of missing facility not found, got %v", err)
+	}
+
+	if err = svc.DeleteFacility(ctx, "1"); err != nil {
+		t.Fatal(err)
+	}
+	if err = svc.DeleteFacility(ctx, "1"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expect second delete not found, got %v", err)
+	}
+	if items, _, _ := svc.GetByItem(ctx, "Tacos", Filter{}, Page{}); len(items) != 0 {
+		t.Fatalf("expect deleted facility out of item slices, got %v", items)
+	}
+	if suggestions := svc.SuggestItems("bur", 0); len(suggestions) != 1 || suggestions[0].Term != "Burgers" {
+		t.Fatalf("expect suggestions updated, got %v", suggestions)
+	}
+
+	// the source is unchanged, but edits are reverted by the next sync
+	report, err := svc.Sync(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Inserted != 1 || report.Deleted != 1 {
+		t.Fatalf("expect edits reverted, got %v", report)
+	}
+	if items, _, _ := svc.GetByItem(ctx, "Tacos", Filter{}, Page{}); len(items) != 1 {
+		t.Fatalf("expect deleted facility restored, got %v", items)
+	}
+}
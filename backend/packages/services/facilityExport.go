@@ -0,0 +1,32 @@
+package services
+
+import (
+	"encoding/csv"
+	"food-trucks/packages/models"
+	"io"
+)
+
+/*
+WriteCSV writes facilities with the columns of data.csv in its order and layouts, so ReadCSV and Seed read them back.
+Rows are written to w as the buffer fills, large lists are not held in memory twice.
+*/
+func WriteCSV(w io.Writer, facilities []models.Facility) error {
+	writer := csv.NewWriter(w)
+	record := make([]string, len(facilityColumns))
+	for i, col := range facilityColumns {
+		record[i] = col.name
+	}
+	if err := writer.Write(record); err != nil {
+		return err
+	}
+	for i := range facilities {
+		for j, col := range facilityColumns {
+			record[j] = col.format(&facilities[i])
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
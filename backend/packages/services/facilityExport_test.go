@@ -0,0 +1,80 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestWriteCSV_RoundTrip(t *testing.T) {
+	file, err := os.Open("../../configs/data.csv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	facilities, _, err := ReadCSV(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err = WriteCSV(&buf, facilities); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile("../../configs/data.csv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	header, _, _ := strings.Cut(buf.String(), "\n")
+	want, _, _ := strings.Cut(string(data), "\n")
+	if header != strings.TrimSpace(want) {
+		t.Fatalf("expect header of data.csv %q, got %q", want, header)
+	}
+
+	read, report, err := ReadCSV(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Skipped) != 0 || len(report.Warnings) != 0 {
+		t.Fatalf("unexpected report %v", report)
+	}
+	if !reflect.DeepEqual(read, facilities) {
+		t.Fatal("expect facilities read back unchanged")
+	}
+}
+
+func TestWriteCSV_Seed(t *testing.T) {
+	ctx := context.Background()
+	facilities, _, err := mustInit().GetByItem(ctx, "tacos", Filter{}, Page{})
+	if err != nil || len(facilities) == 0 {
+		t.Fatalf("expect tacos facilities, got %v %v", facilities, err)
+	}
+	var buf bytes.Buffer
+	if err = WriteCSV(&buf, facilities); err != nil {
+		t.Fatal(err)
+	}
+	p := filepath.Join(t.TempDir(), "tacos.csv")
+	if err = os.WriteFile(p, buf.Bytes(), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	svc := newTestSvc()
+	report, err := svc.Seed(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Imported != len(facilities) {
+		t.Fatalf("expect %d facilities seeded, got %v", len(facilities), report)
+	}
+	seeded, _, err := svc.GetByItem(ctx, "tacos", Filter{}, Page{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(seeded) != len(facilities) {
+		t.Fatalf("expect %d tacos facilities, got %d", len(facilities), len(seeded))
+	}
+}
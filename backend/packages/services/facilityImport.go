@@ -0,0 +1,388 @@
+package services
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"food-trucks/packages/models"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// ImportIssue describes why a row was skipped or a value was ignored, Line is 1-based and counts the header
+type ImportIssue struct {
+	Line   int    `json:"line"`
+	Column string `json:"column,omitempty"`
+	Reason string `json:"reason"`
+}
+
+type ImportReport struct {
+	Rows     int           `json:"rows"`
+	Imported int           `json:"imported"`
+	Skipped  []ImportIssue `json:"skipped"`
+	Warnings []ImportIssue `json:"warnings"`
+}
+
+func (r *ImportReport) String() string {
+	return fmt.Sprintf("imported %d of %d rows, skipped %d, warnings %d",
+		r.Imported, r.Rows, len(r.Skipped), len(r.Warnings))
+}
+
+// facilityColumn maps a column of data.csv to a facility field, columns are matched by normalized header name
+type facilityColumn struct {
+	name     string
+	aliases  []string
+	required bool
+	parse    func(f *models.Facility, value string) error
+	// format is the reverse of parse, used to export facilities as csv
+	format func(f *models.Facility) string
+}
+
+func stringColumn(name string, field func(f *models.Facility) *string, aliases ...string) facilityColumn {
+	return facilityColumn{
+		name:    name,
+		aliases: aliases,
+		parse: func(f *models.Facility, value string) error {
+			*field(f) = value
+			return nil
+		},
+		format: func(f *models.Facility) string {
+			return *field(f)
+		},
+	}
+}
+
+func floatColumn(name string, required bool, field func(f *models.Facility) *float64) facilityColumn {
+	return facilityColumn{
+		name:     name,
+		required: required,
+		parse: func(f *models.Facility, value string) error {
+			if value == "" {
+				return nil
+			}
+			v, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				return fmt.Errorf("%q is not a number", value)
+			}
+			*field(f) = v
+			return nil
+		},
+		format: func(f *models.Facility) string {
+			if v := *field(f); v != 0 {
+				return strconv.FormatFloat(v, 'f', -1, 64)
+			}
+			return ""
+		},
+	}
+}
+
+// dateColumn writes dates with formatDate, data.csv uses different layouts per column
+func dateColumn(name string, formatDate func(t time.Time) string, field func(f *models.Facility) *time.Time) facilityColumn {
+	return facilityColumn{
+		name: name,
+		parse: func(f *models.Facility, value string) error {
+			t, err := models.ParseDate(value)
+			if err != nil {
+				return err
+			}
+			*field(f) = t
+			return nil
+		},
+		format: func(f *models.Facility) string {
+			return formatDate(*field(f))
+		},
+	}
+}
+
+var facilityColumns = []facilityColumn{
+	{
+		name:     "locationid",
+		aliases:  []string{"objectid"},
+		required: true,
+		parse: func(f *models.Facility, value string) error {
+			if value == "" {
+				return errors.New("locationid is empty")
+			}
+			f.LocationID = value
+			return nil
+		},
+		format: func(f *models.Facility) string {
+			return f.LocationID
+		},
+	},
+	stringColumn("Applicant", func(f *models.Facility) *string { return &f.Applicant }),
+	stringColumn("FacilityType", func(f *models.Facility) *string { return &f.FacilityType }),
+	stringColumn("cnn", func(f *models.Facility) *string { return &f.CNN }),
+	stringColumn("LocationDescription", func(f *models.Facility) *string { return &f.LocationDescription }),
+	stringColumn("Address", func(f *models.Facility) *string { return &f.Address }),
+	stringColumn("blocklot", func(f *models.Facility) *string { return &f.BlockLot }),
+	stringColumn("block", func(f *models.Facility) *string { return &f.Block }),
+	stringColumn("lot", func(f *models.Facility) *string { return &f.Lot }),
+	stringColumn("permit", func(f *models.Facility) *string { return &f.Permit }),
+	{
+		name: "Status",
+		parse: func(f *models.Facility, value string) (err error) {
+			f.Status, err = models.ParsePermitStatus(value)
+			return err
+		},
+		format: func(f *models.Facility) string {
+			return string(f.Status)
+		},
+	},
+	{
+		name: "FoodItems",
+		parse: func(f *models.Facility, value string) error {
+			f.FoodItems = models.ParseFoodItems(value)
+			return nil
+		},
+		format: func(f *models.Facility) string {
+			return models.JoinFoodItems(f.FoodItems)
+		},
+	},
+	floatColumn("X", false, func(f *models.Facility) *float64 { return &f.X }),
+	floatColumn("Y", false, func(f *models.Facility) *float64 { return &f.Y }),
+	floatColumn("Latitude", true, func(f *models.Facility) *float64 { return &f.Latitude }),
+	floatColumn("Longitude", true, func(f *models.Facility) *float64 { return &f.Longitude }),
+	stringColumn("Schedule", func(f *models.Facility) *string { return &f.Schedule }),
+	{
+		name: "dayshours",
+		parse: func(f *models.Facility, value string) (err error) {
+			f.DaysHours = value
+			f.Hours, err = models.ParseWeeklySchedule(value)
+			return err
+		},
+		format: func(f *models.Facility) string {
+			return f.DaysHours
+		},
+	},
+	stringColumn("NOISent", func(f *models.Facility) *string { return &f.NOISent }),
+	dateColumn("Approved", models.FormatDateTime, func(f *models.Facility) *time.Time { return &f.Approved }),
+	dateColumn("Received", models.FormatDate, func(f *models.Facility) *time.Time { return &f.Received }),
+	stringColumn("PriorPermit", func(f *models.Facility) *string { return &f.PriorPermit }),
+	dateColumn("ExpirationDate", models.FormatDateTime, func(f *models.Facility) *time.Time { return &f.ExpirationDate }),
+	stringColumn("Location", func(f *models.Facility) *string { return &f.Location }),
+	stringColumn("Fire Prevention Districts", func(f *models.Facility) *string { return &f.FirePreventionDistricts },
+		"Fire Prevention District"),
+	stringColumn("Police Districts", func(f *models.Facility) *string { return &f.PoliceDistricts },
+		"Police District"),
+	stringColumn("Supervisor Districts", func(f *models.Facility) *string { return &f.SupervisorDistricts },
+		"Supervisor District"),
+	stringColumn("Zip Codes", func(f *models.Facility) *string { return &f.ZipCodes },
+		"Zip Code", "zip"),
+	stringColumn("Neighborhoods (old)", func(f *models.Facility) *string { return &f.NeighborhoodsOld },
+		"Neighborhoods"),
+}
+
+// normalizeColumn makes "Fire Prevention Districts", "fire_prevention_districts" and "FirePreventionDistricts" equal
+func normalizeColumn(name string) string {
+	var b strings.Builder
+	for _, r := range strings.TrimPrefix(name, "\ufeff") {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(unicode.ToLower(r))
+		}
+	}
+	return b.String()
+}
+
+func findColumn(header string) *facilityColumn {
+	name := normalizeColumn(header)
+	for i := range facilityColumns {
+		col := &facilityColumns[i]
+		if normalizeColumn(col.name) == name {
+			return col
+		}
+		for _, alias := range col.aliases {
+			if normalizeColumn(alias) == name {
+				return col
+			}
+		}
+	}
+	return nil
+}
+
+// facilityImporter validates rows one by one and collects the report
+type facilityImporter struct {
+	report ImportReport
+	seen   map[string]int
+}
+
+func newFacilityImporter() *facilityImporter {
+	return &facilityImporter{seen: make(map[string]int)}
+}
+
+func (im *facilityImporter) skip(line int, column string, reason string) {
+	im.report.Skipped = append(im.report.Skipped, ImportIssue{Line: line, Column: column, Reason: reason})
+}
+
+func (im *facilityImporter) warn(line int, column string, reason string) {
+	im.report.Warnings = append(im.report.Warnings, ImportIssue{Line: line, Column: column, Reason: reason})
+}
+
+// row converts values keyed by column to a facility, returns false if the row is skipped
+func (im *facilityImporter) row(line int, values map[*facilityColumn]string) (models.Facility, bool) {
+	var facility models.Facility
+	for i := range facilityColumns {
+		col := &facilityColumns[i]
+		value, ok := values[col]
+		if !ok {
+			continue
+		}
+		if err := col.parse(&facility, strings.TrimSpace(value)); err != nil {
+			if col.required {
+				im.skip(line, col.name, err.Error())
+				return facility, false
+			}
+			im.warn(line, col.name, err.Error())
+		}
+	}
+
+	return facility, im.validate(line, facility)
+}
+
+// validate checks a facility parsed from any format, returns false if it is skipped
+func (im *facilityImporter) validate(line int, facility models.Facility) bool {
+	if column, reason := checkFacility(facility); reason != "" {
+		im.skip(line, column, reason)
+		return false
+	}
+	if first, ok := im.seen[facility.LocationID]; ok {
+		im.skip(line, "locationid", fmt.Sprintf("duplicate locationid %s, first seen on line %d", facility.LocationID, first))
+		return false
+	}
+	im.seen[facility.LocationID] = line
+	im.report.Imported++
+	return true
+}
+
+// checkFacility returns why a facility can't be stored and the column at fault, reason is empty if it can
+func checkFacility(facility models.Facility) (column string, reason string) {
+	switch {
+	case facility.LocationID == "":
+		return "locationid", "locationid is empty"
+	case facility.Latitude == 0 && facility.Longitude == 0:
+		return "Latitude", "missing coordinates"
+	case facility.Latitude < -90 || facility.Latitude > 90:
+		return "Latitude", fmt.Sprintf("latitude %v out of range", facility.Latitude)
+	case facility.Longitude < -180 || facility.Longitude > 180:
+		return "Longitude", fmt.Sprintf("longitude %v out of range", facility.Longitude)
+	default:
+		return "", ""
+	}
+}
+
+/*
+normalizeFacility cleans up a facility decoded from json the way csv columns are parsed,
+an invalid value is dropped and reported.
+*/
+func normalizeFacility(facility *models.Facility) []ImportIssue {
+	var issues []ImportIssue
+	facility.LocationID = strings.TrimSpace(facility.LocationID)
+	status, err := models.ParsePermitStatus(string(facility.Status))
+	if err != nil {
+		issues = append(issues, ImportIssue{Column: "Status", Reason: err.Error()})
+	}
+	facility.Status = status
+	if len(facility.Hours) == 0 {
+		if facility.Hours, err = models.ParseWeeklySchedule(facility.DaysHours); err != nil {
+			issues = append(issues, ImportIssue{Column: "dayshours", Reason: err.Error()})
+		}
+	}
+	return issues
+}
+
+/*
+ReadCSV maps columns by header name, so columns can be reordered, renamed (see aliases) or added.
+Invalid rows are reported instead of dropped silently, an error is only returned if the file is unreadable
+or misses a required column.
+*/
+func ReadCSV(r io.Reader) ([]models.Facility, *ImportReport, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1 // rows with wrong field count are reported, not fatal
+	header, err := reader.Read()
+	if err != nil {
+		return nil, nil, fmt.Errorf("fail to read csv header, %w", err)
+	}
+
+	columns := make([]*facilityColumn, len(header))
+	found := make(map[*facilityColumn]bool)
+	for i, name := range header {
+		col := findColumn(name)
+		if col != nil && found[col] {
+			return nil, nil, fmt.Errorf("column %q appears more than once", name)
+		}
+		columns[i] = col
+		found[col] = true
+	}
+	for i := range facilityColumns {
+		if col := &facilityColumns[i]; col.required && !found[col] {
+			return nil, nil, fmt.Errorf("missing required column %q", col.name)
+		}
+	}
+
+	im := newFacilityImporter()
+	var facilities []models.Facility
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			im.report.Rows++
+			im.skip(parseErr.StartLine, "", parseErr.Err.Error())
+			continue
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		im.report.Rows++
+		line, _ := reader.FieldPos(0)
+		if len(record) != len(header) {
+			im.skip(line, "", fmt.Sprintf("expect %d fields, got %d", len(header), len(record)))
+			continue
+		}
+		values := make(map[*facilityColumn]string, len(columns))
+		for i, col := range columns {
+			if col != nil {
+				values[col] = record[i]
+			}
+		}
+		if facility, ok := im.row(line, values); ok {
+			facilities = append(facilities, facility)
+		}
+	}
+	return facilities, &im.report, nil
+}
+
+/*
+ReadJSON reads an array of facilities in the format the api returns, validated the same as csv rows.
+Line of issues is the 1-based index in the array.
+*/
+func ReadJSON(r io.Reader) ([]models.Facility, *ImportReport, error) {
+	var raws []json.RawMessage
+	if err := json.NewDecoder(r).Decode(&raws); err != nil {
+		return nil, nil, fmt.Errorf("fail to read json array, %w", err)
+	}
+	im := newFacilityImporter()
+	var facilities []models.Facility
+	for i, raw := range raws {
+		line := i + 1
+		im.report.Rows++
+		var facility models.Facility
+		if err := json.Unmarshal(raw, &facility); err != nil {
+			im.skip(line, "", err.Error())
+			continue
+		}
+		for _, issue := range normalizeFacility(&facility) {
+			im.warn(line, issue.Column, issue.Reason)
+		}
+		if im.validate(line, facility) {
+			facilities = append(facilities, facility)
+		}
+	}
+	return facilities, &im.report, nil
+}
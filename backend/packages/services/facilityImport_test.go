@@ -0,0 +1,106 @@
+package services
+
+import (
+	"food-trucks/packages/models"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestReadCSV_DataFile(t *testing.T) {
+	file, err := os.Open("../../configs/data.csv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	facilities, report, err := ReadCSV(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Rows != 629 || report.Imported != len(facilities) || report.Imported+len(report.Skipped) != report.Rows {
+		t.Fatalf("unexpected report %v", report)
+	}
+	for _, issue := range report.Skipped {
+		if issue.Reason != "missing coordinates" {
+			t.Fatalf("unexpected skip %+v", issue)
+		}
+	}
+}
+
+func TestReadCSV_HeaderMapping(t *testing.T) {
+	csv := `Longitude,Latitude,Applicant,LocationID,Extra,fire_prevention_district,NEIGHBORHOODS (OLD)
+-122.41,37.80,Anzu,1569152,ignored,5,23
+`
+	facilities, report, err := ReadCSV(strings.NewReader(csv))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(facilities) != 1 || len(report.Skipped) != 0 {
+		t.Fatalf("unexpected result %v %v", facilities, report)
+	}
+	f := facilities[0]
+	if f.LocationID != "1569152" || f.Applicant != "Anzu" || f.Latitude != 37.80 || f.Longitude != -122.41 ||
+		f.FirePreventionDistricts != "5" || f.NeighborhoodsOld != "23" {
+		t.Fatalf("columns mapped wrong %+v", f)
+	}
+}
+
+func TestReadCSV_InvalidRows(t *testing.T) {
+	csv := `locationid,Applicant,X,Latitude,Longitude
+1,ok,abc,37.80,-122.41
+2,short row
+3,bad lat,1,north,-122.41
+4,out of range,1,91,-122.41
+1,duplicate,1,37.80,-122.41
+,no id,1,37.80,-122.41
+5,no location,1,0,0
+`
+	facilities, report, err := ReadCSV(strings.NewReader(csv))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(facilities) != 1 || facilities[0].LocationID != "1" {
+		t.Fatalf("expect only row 1, got %v", facilities)
+	}
+	expectLines := []int{3, 4, 5, 6, 7, 8}
+	if len(report.Skipped) != len(expectLines) {
+		t.Fatalf("unexpected skipped %+v", report.Skipped)
+	}
+	for i, line := range expectLines {
+		if report.Skipped[i].Line != line || report.Skipped[i].Reason == "" {
+			t.Fatalf("expect line %d skipped, got %+v", line, report.Skipped[i])
+		}
+	}
+	if len(report.Warnings) != 1 || report.Warnings[0].Line != 2 || report.Warnings[0].Column != "X" {
+		t.Fatalf("expect warning on X of line 2, got %+v", report.Warnings)
+	}
+}
+
+func TestReadCSV_MissingColumn(t *testing.T) {
+	if _, _, err := ReadCSV(strings.NewReader("locationid,Applicant,Latitude\n1,a,37.8\n")); err == nil {
+		t.Fatal("expect error for missing Longitude column")
+	}
+}
+
+func TestReadJSON(t *testing.T) {
+	data := `[
+	{"locationID":"1","applicant":"Taqueria","status":"approved","foodItems":"Tacos: Burritos","latitude":37.79,"longitude":-122.39,"daysHours":"Mo-Fr:7AM-8AM"},
+	{"locationID":"1","latitude":37.79,"longitude":-122.39},
+	{"locationID":"2","latitude":0,"longitude":0},
+	{"locationID":"3","status":"CLOSED","foodItems":["Pizza"],"latitude":37.79,"longitude":-122.39},
+	"not a facility"
+]`
+	facilities, report, err := ReadJSON(strings.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Rows != 5 || report.Imported != 2 || len(report.Skipped) != 3 || len(report.Warnings) != 1 {
+		t.Fatalf("unexpected report %+v", report)
+	}
+	if f := facilities[0]; f.Status != models.PermitApproved || len(f.FoodItems) != 2 || len(f.Hours) != 5 {
+		t.Fatalf("unexpected facility %+v", f)
+	}
+	if _, _, err := ReadJSON(strings.NewReader(`{}`)); err == nil {
+		t.Fatal("expect error for non array")
+	}
+}
@@ -0,0 +1,221 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"food-trucks/packages/models"
+	"food-trucks/packages/util/socrata"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	CSVSource     = "csv"
+	SocrataSource = "socrata"
+)
+
+// ErrNotModified is returned by a source whose content is unchanged since the validator of the last load
+var ErrNotModified = errors.New("dataset not modified")
+
+// Dataset is facilities loaded from a source, Version changes whenever the content changes
+type Dataset struct {
+	Version    string
+	Facilities []models.Facility
+	Report     *ImportReport
+	// Validator tells cheaply if the source changed since this load, e.g. a file's mtime or an http ETag
+	Validator string
+}
+
+/*
+FacilitySource loads the whole dataset, Sync applies it to the stores.
+since is the Validator of the last load, empty for an unconditional load.
+*/
+type FacilitySource interface {
+	Load(ctx context.Context, since string) (*Dataset, error)
+}
+
+type SocrataConfig struct {
+	// URL is the SODA json endpoint, e.g. https://data.sfgov.org/resource/rqzj-sfat.json
+	URL      string `yaml:"url"`
+	AppToken string `yaml:"appToken"`
+	// Where is a SoQL filter, e.g. status = 'APPROVED'
+	Where    string `yaml:"where"`
+	PageSize int    `yaml:"pageSize"`
+}
+
+type SourceConfig struct {
+	// Type is "csv" (the default) or "socrata"
+	Type    string        `yaml:"type"`
+	Path    string        `yaml:"path"`
+	Socrata SocrataConfig `yaml:"socrata"`
+	// RefreshInterval is between checks of the source for changes, e.g. 10m, 0 disables refreshing
+	RefreshInterval time.Duration `yaml:"refreshInterval"`
+}
+
+func NewFacilitySource(config SourceConfig) (FacilitySource, error) {
+	switch config.Type {
+	case "", CSVSource:
+		if config.Path == "" {
+			return nil, fmt.Errorf("csv source needs a path")
+		}
+		return NewCSVFacilitySource(config.Path), nil
+	case SocrataSource:
+		if config.Socrata.URL == "" {
+			return nil, fmt.Errorf("socrata source needs an url")
+		}
+		client := socrata.NewClient(config.Socrata.URL).
+			WithAppToken(config.Socrata.AppToken).WithPageSize(config.Socrata.PageSize)
+		return NewSocrataFacilitySource(client, socrata.Query{Where: config.Socrata.Where}), nil
+	default:
+		return nil, fmt.Errorf("unknown source %q, should be %q or %q", config.Type, CSVSource, SocrataSource)
+	}
+}
+
+func contentHash(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// CSVFacilitySource reads a file in the format of data.csv
+type CSVFacilitySource struct {
+	path string
+}
+
+func NewCSVFacilitySource(path string) *CSVFacilitySource {
+	return &CSVFacilitySource{path: path}
+}
+
+func (s *CSVFacilitySource) String() string {
+	return "csv " + s.path
+}
+
+// Load compares the file's size and mtime with since
+func (s *CSVFacilitySource) Load(ctx context.Context, since string) (*Dataset, error) {
+	info, err := os.Stat(s.path)
+	if err != nil {
+		return nil, err
+	}
+	validator := fmt.Sprintf("%d-%d", info.ModTime().UnixNano(), info.Size())
+	if since != "" && since == validator {
+		return nil, ErrNotModified
+	}
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		return nil, err
+	}
+	facilities, report, err := ReadCSV(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	return &Dataset{Version: contentHash(data), Facilities: facilities, Report: report, Validator: validator}, nil
+}
+
+/*
+SocrataFacilitySource reads the open data portal's json feed of the same dataset as data.csv.
+Its fields are the csv columns in lower case, they are mapped and validated the same way as csv columns.
+*/
+type SocrataFacilitySource struct {
+	client *socrata.Client
+	query  socrata.Query
+}
+
+func NewSocrataFacilitySource(client *socrata.Client, query socrata.Query) *SocrataFacilitySource {
+	return &SocrataFacilitySource{client: client, query: query}
+}
+
+func (s *SocrataFacilitySource) String() string {
+	return "socrata " + s.client.URL()
+}
+
+// Load makes a conditional request with since, an ETag or a Last-Modified
+func (s *SocrataFacilitySource) Load(ctx context.Context, since string) (*Dataset, error) {
+	rows, validator, err := s.client.FetchIfModified(ctx, s.query, parseValidator(since))
+	if errors.Is(err, socrata.ErrNotModified) {
+		return nil, ErrNotModified
+	}
+	if err != nil {
+		return nil, err
+	}
+	im := newFacilityImporter()
+	var facilities []models.Facility
+	var content bytes.Buffer
+	for i, row := range rows {
+		line := i + 1
+		im.report.Rows++
+		content.Write(row)
+		var fields map[string]any
+		if err := json.Unmarshal(row, &fields); err != nil {
+			im.skip(line, "", err.Error())
+			continue
+		}
+		values := make(map[*facilityColumn]string, len(fields))
+		for name, v := range fields {
+			if col := findColumn(name); col != nil {
+				values[col] = socrataValue(v)
+			}
+		}
+		if facility, ok := im.row(line, values); ok {
+			facilities = append(facilities, facility)
+		}
+	}
+	return &Dataset{
+		Version:    contentHash(content.Bytes()),
+		Facilities: facilities,
+		Report:     &im.report,
+		Validator:  formatValidator(validator),
+	}, nil
+}
+
+const (
+	etagPrefix     = "etag "
+	modifiedPrefix = "modified "
+)
+
+// formatValidator keeps the ETag if the portal sends one, it is more precise than Last-Modified
+func formatValidator(v socrata.Validator) string {
+	switch {
+	case v.ETag != "":
+		return etagPrefix + v.ETag
+	case v.LastModified != "":
+		return modifiedPrefix + v.LastModified
+	default:
+		return ""
+	}
+}
+
+func parseValidator(s string) socrata.Validator {
+	if etag, ok := strings.CutPrefix(s, etagPrefix); ok {
+		return socrata.Validator{ETag: etag}
+	}
+	if modified, ok := strings.CutPrefix(s, modifiedPrefix); ok {
+		return socrata.Validator{LastModified: modified}
+	}
+	return socrata.Validator{}
+}
+
+// socrataValue formats a json value as it is written in data.csv, a location is written as "(lat, lon)"
+func socrataValue(v any) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	case map[string]any:
+		lat, lon := socrataValue(v["latitude"]), socrataValue(v["longitude"])
+		if lat == "" || lon == "" {
+			return ""
+		}
+		return fmt.Sprintf("(%s, %s)", lat, lon)
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,73 @@
+package services
+
+import (
+	"context"
+	"food-trucks/packages/models"
+	"food-trucks/packages/util/socrata"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// socrataRows are shaped like rows of https://data.sfgov.org/resource/rqzj-sfat.json
+const socrataRows = `[
+{"objectid":"1569152","applicant":"Anzu","facilitytype":"Truck","status":"APPROVED",
+ "fooditems":"Tacos: Burritos","x":"6013245.668","latitude":"37.79","longitude":"-122.39",
+ "dayshours":"Mo-Fr:7AM-8AM","approved":"2021-11-05T00:00:00.000","expirationdate":"2022-11-15T00:00:00.000",
+ "location":{"latitude":"37.79","longitude":"-122.39","human_address":"{}"},":@computed_region_yftq_j783":"5"},
+{"objectid":"1569153","applicant":"No Location","latitude":"0","longitude":"0"},
+{"objectid":"1569154","applicant":"Numbers","latitude":37.8,"longitude":-122.4}
+]`
+
+func TestSocrataFacilitySource_Load(t *testing.T) {
+	feed := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("$offset") != "0" {
+			_, _ = w.Write([]byte("[]"))
+			return
+		}
+		_, _ = w.Write([]byte(socrataRows))
+	}))
+	defer feed.Close()
+
+	source := NewSocrataFacilitySource(socrata.NewClient(feed.URL).WithPageSize(3), socrata.Query{})
+	dataset, err := source.Load(context.Background(), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dataset.Report.Rows != 3 || len(dataset.Facilities) != 2 || len(dataset.Report.Skipped) != 1 {
+		t.Fatalf("unexpected report %v", dataset.Report)
+	}
+	f := dataset.Facilities[0]
+	if f.LocationID != "1569152" || f.Status != models.PermitApproved || len(f.FoodItems) != 2 ||
+		f.X != 6013245.668 || f.ExpirationDate.Year() != 2022 || len(f.Hours) != 5 || f.Location != "(37.79, -122.39)" {
+		t.Fatalf("unexpected facility %+v", f)
+	}
+	if dataset.Facilities[1].Latitude != 37.8 {
+		t.Fatalf("expect numbers accepted, got %+v", dataset.Facilities[1])
+	}
+
+	// the same feed is the same version, so a second sync writes nothing
+	svc := newTestSvc()
+	if _, err = svc.SyncSource(context.Background(), source); err != nil {
+		t.Fatal(err)
+	}
+	report, err := svc.SyncSource(context.Background(), source)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !report.Unchanged {
+		t.Fatalf("expect unchanged, got %v", report)
+	}
+}
+
+func TestNewFacilitySource(t *testing.T) {
+	if _, err := NewFacilitySource(SourceConfig{Type: "ftp"}); err == nil {
+		t.Fatal("expect unknown source type to fail")
+	}
+	if _, err := NewFacilitySource(SourceConfig{Type: SocrataSource}); err == nil {
+		t.Fatal("expect socrata without url to fail")
+	}
+	if _, err := NewFacilitySource(SourceConfig{Path: "../../configs/data.csv"}); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -2,163 +2,178 @@ package services
 
 import (
 	"context"
-	"encoding/csv"
 	"fmt"
 	"food-trucks/packages/models"
 	"food-trucks/packages/util/errs"
-	"os"
-	"strconv"
-	"strings"
+	"food-trucks/packages/util/search"
+	"sync"
 )
 
 type Location struct {
-	Lat float64
-	Lon float64
+	Lat float64 `json:"lat"`
+	Lon float64 `json:"lon"`
 }
+
+// FacilityDistance is a facility with its distance to the queried point in meters
+type FacilityDistance struct {
+	Facility models.Facility `json:"facility"`
+	Distance float64         `json:"distance"`
+}
+
 type FacilitySvc struct {
 	FacilityStore     FacilityStore
 	ItemFacilityStore ItemFacilityStore
 	GeoFacilityStore  GeoFacilityStore
-	Center            Location
+	DatasetStore      DatasetStore
+	// TileStore is optional, see WithTileStore
+	TileStore TileStore
+	// syncMu serializes syncs and imports
+	syncMu sync.Mutex
+	// mu guards state derived in process from all facilities, which a sync replaces
+	mu sync.RWMutex
+	// locations are of all facilities by LocationID, the extent is computed from them
+	locations map[string]Location
+	extent    Extent
+	// revision is of the facilities stored, cached tiles are keyed by it
+	revision    string
+	searchIndex *search.Index[string]
+	foodItems   *search.Suggester
+}
+
+func NewFacilitySvc(
+	facilityStore FacilityStore,
+	itemFacilityStore ItemFacilityStore,
+	geoFacilityStore GeoFacilityStore,
+	datasetStore DatasetStore,
+) *FacilitySvc {
+	return &FacilitySvc{
+		FacilityStore:     facilityStore,
+		ItemFacilityStore: itemFacilityStore,
+		GeoFacilityStore:  geoFacilityStore,
+		DatasetStore:      datasetStore,
+		searchIndex:       search.NewIndex[string](),
+		foodItems:         search.NewSuggester(),
+	}
 }
 
+// GetByID returns the facility of LocationID id, it fails with ErrNotFound if there is none
 func (t *FacilitySvc) GetByID(ctx context.Context, id string) (models.Facility, error) {
-	var ret models.Facility
-	items, err := t.FacilityStore.Get(ctx, []string{id})
+	facility, err := t.getFacility(ctx, id)
 	if err != nil {
-		return ret, err
+		return models.Facility{}, err
 	}
-	if len(items) == 0 {
-		return ret, fmt.Errorf("not found facility " + id)
+	if facility == nil {
+		return models.Facility{}, fmt.Errorf("%w: facility %s", ErrNotFound, id)
 	}
-	return items[0], nil
+	return *facility, nil
 }
 
-func (t *FacilitySvc) GetByItem(ctx context.Context, item string) ([]models.Facility, error) {
-	return t.ItemFacilityStore.GetAllMemberEntities(ctx, strings.TrimSpace(item))
+// GetByItem returns a page of facilities serving the food item, and the next page, nil if it's the last
+func (t *FacilitySvc) GetByItem(ctx context.Context, item string, filter Filter, page Page) ([]models.Facility, *Page, error) {
+	key := models.FoodItemKey(item)
+	return paginate(page, filter, func(offset int, count int) ([]models.Facility, error) {
+		return t.ItemFacilityStore.GetMemberEntities(ctx, key, offset, count)
+	})
 }
 
-func (t *FacilitySvc) GetByLocation(ctx context.Context, lat, lon, radius float64) ([]models.Facility, error) {
-	if lon == 0 || lat == 0 && radius == 0 {
-		lat = t.Center.Lat
-		lon = t.Center.Lon
-		radius = 1
-	}
-	return t.GeoFacilityStore.Get(ctx, lat, lon, radius)
+// GetByLocation returns a page of facilities within radius (km) of the point, nearest first
+func (t *FacilitySvc) GetByLocation(ctx context.Context, lat, lon, radius float64, filter Filter, page Page) ([]models.Facility, *Page, error) {
+	return paginate(page, filter, func(offset int, count int) ([]models.Facility, error) {
+		if count > 0 {
+			count += offset
+		}
+		facilities, _, err := t.GeoFacilityStore.GetNearest(ctx, lat, lon, radius, count)
+		return skip(facilities, offset), err
+	})
 }
 
-func (t *FacilitySvc) Seed(p string) error {
-	ctx := context.Background()
-	facilities, err := t.readCSV(p)
-	if err != nil {
-		return errs.Errf("Fail to read csv %w", err)
+// GetByBox returns a page of facilities inside a map viewport, nearest to its center first
+func (t *FacilitySvc) GetByBox(ctx context.Context, box BBox, filter Filter, page Page) ([]models.Facility, *Page, error) {
+	if err := box.Validate(); err != nil {
+		return nil, nil, err
 	}
-	t.getCenter(facilities)
-	if err = t.cacheFacilities(ctx, facilities); err != nil {
-		return errs.Errf("failed to cache facilities, %w", err)
-	}
-	if err = t.cacheFoodItems(ctx, facilities); err != nil {
-		return errs.Errf("failed to cache food items, %w", err)
+	return paginate(page, filter, func(offset int, count int) ([]models.Facility, error) {
+		if count > 0 {
+			count += offset
+		}
+		facilities, err := t.GeoFacilityStore.GetInBox(ctx, box.MinLat, box.MinLon, box.MaxLat, box.MaxLon, count)
+		return skip(facilities, offset), err
+	})
+}
+
+/*
+GetNearest returns at most count facilities nearest to the point, sorted by distance ascending.
+radius is in km, radius <= 0 means no limit.
+*/
+func (t *FacilitySvc) GetNearest(ctx context.Context, lat, lon float64, count int, radius float64, filter Filter) ([]FacilityDistance, error) {
+	return nearest(count, filter, func(limit int) ([]models.Facility, []float64, error) {
+		return t.GeoFacilityStore.GetNearest(ctx, lat, lon, radius, limit)
+	})
+}
+
+/*
+GetNearestByItem returns at most count facilities serving the food item nearest to the point,
+sorted by distance ascending. The item index and the geo index are intersected by the store.
+radius is in km, radius <= 0 means no limit.
+*/
+func (t *FacilitySvc) GetNearestByItem(ctx context.Context, item string, lat, lon float64, count int, radius float64, filter Filter) ([]FacilityDistance, error) {
+	key := models.FoodItemKey(item)
+	if key == "" {
+		return nil, fmt.Errorf("%w: item should not be empty", ErrInvalidArgument)
 	}
-	return t.cacheLocations(ctx, facilities)
+	return nearest(count, filter, func(limit int) ([]models.Facility, []float64, error) {
+		return t.GeoFacilityStore.GetNearestInSlice(ctx, key, lat, lon, radius, limit)
+	})
 }
 
-func (t *FacilitySvc) getCenter(facilities []models.Facility) {
-	var lon, lat float64
-	for _, facility := range facilities {
-		lon += facility.Longitude
-		lat += facility.Latitude
+// nearest applies filter to facilities sorted by distance, fetch gets at most limit of them, 0 means all
+func nearest(count int, filter Filter, fetch func(limit int) ([]models.Facility, []float64, error)) ([]FacilityDistance, error) {
+	if count <= 0 {
+		return nil, fmt.Errorf("%w: count should be positive", ErrInvalidArgument)
+	}
+	limit := count
+	if !filter.IsEmpty() {
+		// can not know how many will be filtered out, fetch all within radius then truncate
+		limit = 0
 	}
-	t.Center.Lat = lat / float64(len(facilities))
-	t.Center.Lon = lon / float64(len(facilities))
+	facilities, dists, err := fetch(limit)
+	if err != nil {
+		return nil, err
+	}
+	ret := make([]FacilityDistance, 0, count)
+	for i, facility := range facilities {
+		if len(ret) == count {
+			break
+		}
+		if filter.Match(facility) {
+			ret = append(ret, FacilityDistance{Facility: facility, Distance: dists[i]})
+		}
+	}
+	return ret, nil
 }
 
 func (t *FacilitySvc) cacheLocations(ctx context.Context, facilities []models.Facility) error {
-	for _, facility := range facilities {
-		if err := t.GeoFacilityStore.Add(ctx, facility); err != nil {
-			return errs.Errf("Fail to seed location data", err)
-		}
+	if err := t.GeoFacilityStore.AddAll(ctx, facilities); err != nil {
+		return errs.Errf("Fail to seed location data, %w", err)
 	}
 	return nil
 }
 
+// cacheFoodItems adds facilities to the slices of their items, facilities themselves are cached by cacheFacilities
 func (t *FacilitySvc) cacheFoodItems(ctx context.Context, facilities []models.Facility) error {
+	members := make(map[any][]models.Facility)
 	for _, facility := range facilities {
-		for _, item := range strings.Split(facility.FoodItems, ":") {
-			item = strings.TrimSpace(item)
-			if err := t.ItemFacilityStore.AddMem(ctx, item, []models.Facility{facility}); err != nil {
-				return errs.Errf("Fail to seed food items, %w", err)
-			}
+		for _, item := range facility.FoodItems {
+			key := models.FoodItemKey(item)
+			members[key] = append(members[key], facility)
 		}
 	}
+	if err := t.ItemFacilityStore.AddMembers(ctx, members); err != nil {
+		return errs.Errf("Fail to seed food items, %w", err)
+	}
 	return nil
 }
 
 func (t *FacilitySvc) cacheFacilities(ctx context.Context, facilities []models.Facility) error {
 	return t.FacilityStore.Set(ctx, facilities)
 }
-
-func (t *FacilitySvc) readCSV(p string) ([]models.Facility, error) {
-	file, err := os.Open(p)
-	if err != nil {
-		return nil, err
-	}
-	defer file.Close()
-	reader := csv.NewReader(file)
-	reader.FieldsPerRecord = -1 // allows variable number of fields per record
-	records, err := reader.ReadAll()
-	if err != nil {
-		return nil, err
-	}
-
-	var facilities []models.Facility
-
-	// Skip the header row
-	for i, record := range records {
-		if i == 0 {
-			continue
-		}
-
-		x, _ := strconv.ParseFloat(record[12], 64)
-		y, _ := strconv.ParseFloat(record[13], 64)
-		latitude, _ := strconv.ParseFloat(record[14], 64)
-		longitude, _ := strconv.ParseFloat(record[15], 64)
-
-		facility := models.Facility{
-			LocationID:              record[0],
-			Applicant:               record[1],
-			FacilityType:            record[2],
-			CNN:                     record[3],
-			LocationDescription:     record[4],
-			Address:                 record[5],
-			BlockLot:                record[6],
-			Block:                   record[7],
-			Lot:                     record[8],
-			Permit:                  record[9],
-			Status:                  record[10],
-			FoodItems:               record[11],
-			X:                       x,
-			Y:                       y,
-			Latitude:                latitude,
-			Longitude:               longitude,
-			Schedule:                record[16],
-			DaysHours:               record[17],
-			NOISent:                 record[18],
-			Approved:                record[19],
-			Received:                record[20],
-			PriorPermit:             record[21],
-			ExpirationDate:          record[22],
-			Location:                record[23],
-			FirePreventionDistricts: record[24],
-			PoliceDistricts:         record[25],
-			SupervisorDistricts:     record[26],
-			ZipCodes:                record[27],
-			NeighborhoodsOld:        record[28],
-		}
-		if facility.Longitude > 0 || facility.Latitude > 0 {
-			facilities = append(facilities, facility)
-		}
-	}
-
-	return facilities, nil
-}
@@ -2,38 +2,151 @@ package services
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"food-trucks/packages/models"
-	"food-trucks/packages/util/rdb"
+	"food-trucks/packages/util/memdb"
+	"slices"
 	"testing"
 )
 
 func TestFacilitySvc_GetByLocation(t *testing.T) {
 	ctx := context.Background()
 	svc := mustInit()
-	items, err := svc.GetByLocation(ctx, 37.805885350100986, -122.41594524663745, 1)
+	items, _, err := svc.GetByLocation(ctx, 37.805885350100986, -122.41594524663745, 1, Filter{}, Page{})
 	if err != nil {
 		t.Fatal(err)
 	}
+	if len(items) == 0 {
+		t.Fatal("expect facilities near Fisherman's Wharf")
+	}
 	fmt.Println(items)
 }
 
-func mustInit() *FacilitySvc {
-	config := rdb.Config{}
-	facilityStore := rdb.NewEntityStore[string, models.Facility]("facility", 0, config).
+func TestFacilitySvc_GetByID(t *testing.T) {
+	ctx := context.Background()
+	svc := mustInit()
+	facility, err := svc.GetByID(ctx, "1569152")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if facility.LocationID != "1569152" {
+		t.Fatalf("unexpected facility %v", facility)
+	}
+	if _, err = svc.GetByID(ctx, "missing"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expect not found, got %v", err)
+	}
+}
+
+func TestFacilitySvc_GetByItem(t *testing.T) {
+	ctx := context.Background()
+	svc := mustInit()
+	items, _, err := svc.GetByItem(ctx, " Noodles\n", Filter{}, Page{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) == 0 {
+		t.Fatal("expect facilities serving noodles")
+	}
+	for _, item := range items {
+		if item.LocationID == "" {
+			t.Fatalf("unexpected empty facility %v", item)
+		}
+	}
+}
+
+// newTestSvc wires in memory stores, so service tests don't need a redis
+func newTestSvc() *FacilitySvc {
+	facilityStore := memdb.NewEntityStore[string, models.Facility]().
 		WithGetKey(models.GetFacilityKey)
-	itemFacilityStore := rdb.NewSliceStore[string, models.Facility]("item", 0, config, facilityStore).
+	itemFacilityStore := memdb.NewSliceStore[string, models.Facility](facilityStore).
 		WithGetKey(models.GetFacilityKey).WithGetScore(models.GetFacilityScore)
-	geoFacilityStore := rdb.NewGeoStore[string, models.Facility]("geo", 0, config, facilityStore).
-		WithGetKey(models.GetFacilityKey).WithGetLocation(models.GetFacilityLocation)
-	facilitySvc := &FacilitySvc{
-		FacilityStore:     facilityStore,
-		ItemFacilityStore: itemFacilityStore,
-		GeoFacilityStore:  geoFacilityStore,
-	}
-	err := facilitySvc.Seed("../..//configs/data.csv")
+	geoFacilityStore := memdb.NewGeoStore[string, models.Facility](facilityStore).
+		WithGetKey(models.GetFacilityKey).WithGetLocation(models.GetFacilityLocation).
+		WithSliceStore(itemFacilityStore)
+	datasetStore := memdb.NewEntityStore[string, DatasetState]().WithGetKey(GetDatasetStateKey)
+	return NewFacilitySvc(facilityStore, itemFacilityStore, geoFacilityStore, datasetStore)
+}
+
+// mustInit seeds data.csv to in memory stores
+func mustInit() *FacilitySvc {
+	facilitySvc := newTestSvc()
+	_, err := facilitySvc.Seed("../..//configs/data.csv")
 	if err != nil {
 		panic(err)
 	}
 	return facilitySvc
 }
+
+func TestFacilitySvc_GetNearest(t *testing.T) {
+	ctx := context.Background()
+	svc := mustInit()
+	items, err := svc.GetNearest(ctx, 37.7955, -122.3937, 5, 0, Filter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 5 {
+		t.Fatalf("expect 5 facilities, got %v", items)
+	}
+	for i := 1; i < len(items); i++ {
+		if items[i].Distance < items[i-1].Distance {
+			t.Fatalf("expect ascending distances, got %v", items)
+		}
+	}
+
+	filter := Filter{Statuses: []models.PermitStatus{models.PermitIssued}}
+	items, err = svc.GetNearest(ctx, 37.7955, -122.3937, 5, 0, filter)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 2 {
+		t.Fatalf("expect both issued permits, got %v", items)
+	}
+}
+
+func TestFacilitySvc_GetByBox(t *testing.T) {
+	ctx := context.Background()
+	svc := mustInit()
+	box := BBox{MinLat: 37.78, MinLon: -122.42, MaxLat: 37.80, MaxLon: -122.39}
+	items, _, err := svc.GetByBox(ctx, box, Filter{}, Page{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) == 0 {
+		t.Fatal("expect facilities downtown")
+	}
+	for _, item := range items {
+		if !box.Contains(item.Latitude, item.Longitude) {
+			t.Fatalf("facility %v is outside of box", item.LocationID)
+		}
+	}
+
+	box.MinLat, box.MaxLat = box.MaxLat, box.MinLat
+	if _, _, err := svc.GetByBox(ctx, box, Filter{}, Page{}); !errors.Is(err, ErrInvalidArgument) {
+		t.Fatalf("expect invalid argument, got %v", err)
+	}
+}
+
+func TestFacilitySvc_GetNearestByItem(t *testing.T) {
+	ctx := context.Background()
+	svc := mustInit()
+	items, err := svc.GetNearestByItem(ctx, "Tacos", 37.7955, -122.3937, 5, 3, Filter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 5 {
+		t.Fatalf("expect 5 facilities, got %v", items)
+	}
+	for i, item := range items {
+		if !slices.ContainsFunc(item.Facility.FoodItems, func(s string) bool { return models.FoodItemKey(s) == "tacos" }) {
+			t.Fatalf("facility %v does not serve tacos", item.Facility.LocationID)
+		}
+		if item.Distance > 3000 || i > 0 && item.Distance < items[i-1].Distance {
+			t.Fatalf("expect ascending distances within radius, got %v", items)
+		}
+	}
+
+	if _, err = svc.GetNearestByItem(ctx, " ", 37.7955, -122.3937, 5, 3, Filter{}); !errors.Is(err, ErrInvalidArgument) {
+		t.Fatalf("expect invalid argument, got %v", err)
+	}
+}
@@ -0,0 +1,344 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"food-trucks/packages/models"
+	"food-trucks/packages/util/errs"
+	"food-trucks/packages/util/search"
+	"io"
+	"time"
+)
+
+// facilityDataset is the name DatasetState of facilities is stored under
+const facilityDataset = "facilities"
+
+// DatasetState records the dataset last applied to the stores, so the next sync only writes the difference
+type DatasetState struct {
+	Name      string    `json:"name"`
+	Version   string    `json:"version"`
+	AppliedAt time.Time `json:"appliedAt"`
+	// Hashes is content hash by LocationID of every stored facility
+	Hashes map[string]string `json:"hashes"`
+}
+
+func GetDatasetStateKey(s DatasetState) string {
+	return s.Name
+}
+
+type SyncReport struct {
+	Version   string        `json:"version"`
+	Unchanged bool          `json:"unchanged"`
+	Inserted  int           `json:"inserted"`
+	Updated   int           `json:"updated"`
+	Deleted   int           `json:"deleted"`
+	Import    *ImportReport `json:"import"`
+}
+
+func (r *SyncReport) String() string {
+	if r.Unchanged {
+		return fmt.Sprintf("dataset %.8s unchanged, %v", r.Version, r.Import)
+	}
+	return fmt.Sprintf("dataset %.8s applied, inserted %d, updated %d, deleted %d, %v",
+		r.Version, r.Inserted, r.Updated, r.Deleted, r.Import)
+}
+
+// facilityDiff is what a sync writes, facilities are new versions, removed are LocationIDs
+type facilityDiff struct {
+	inserted []models.Facility
+	updated  []models.Facility
+	removed  []string
+	hashes   map[string]string
+}
+
+/*
+Sync applies the csv to the stores incrementally: facilities are compared with the last applied dataset
+by LocationID and content hash, only inserted, updated and removed ones are written, including stale
+item slice and geo members. Nothing is written if the file is the version last applied.
+Without a last applied dataset, e.g. the first sync over stores seeded by an older version, every stored
+facility is reconciled: the ones missing in the csv are removed, so are geo members and item slices of none of it.
+*/
+func (t *FacilitySvc) Sync(p string) (*SyncReport, error) {
+	return t.SyncSource(context.Background(), NewCSVFacilitySource(p))
+}
+
+// SyncSource is Sync for a dataset of any source
+func (t *FacilitySvc) SyncSource(ctx context.Context, source FacilitySource) (*SyncReport, error) {
+	dataset, err := source.Load(ctx, "")
+	if err != nil {
+		return nil, errs.Errf("Fail to load dataset %w", err)
+	}
+	return t.ApplyDataset(ctx, dataset)
+}
+
+// ApplyDataset writes the difference of a loaded dataset to the stores
+func (t *FacilitySvc) ApplyDataset(ctx context.Context, dataset *Dataset) (*SyncReport, error) {
+	return t.apply(ctx, dataset, false)
+}
+
+// Seed loads facilities from csv rewriting all of them, the report lists rows skipped by validation
+func (t *FacilitySvc) Seed(p string) (*ImportReport, error) {
+	ctx := context.Background()
+	dataset, err := NewCSVFacilitySource(p).Load(ctx, "")
+	if err != nil {
+		return nil, errs.Errf("Fail to read csv %w", err)
+	}
+	report, err := t.apply(ctx, dataset, true)
+	return report.Import, err
+}
+
+/*
+ImportCSV replaces the dataset with an uploaded csv, validated as data.csv.
+Facilities missing in the upload are removed, an upload without any valid facility is rejected.
+The upload isn't written back to the source, so the next sync from a changed source or a restart reverts it.
+*/
+func (t *FacilitySvc) ImportCSV(ctx context.Context, r io.Reader) (*SyncReport, error) {
+	return t.importData(ctx, r, ReadCSV)
+}
+
+// ImportJSON is ImportCSV for an array of facilities in the format the api returns
+func (t *FacilitySvc) ImportJSON(ctx context.Context, r io.Reader) (*SyncReport, error) {
+	return t.importData(ctx, r, ReadJSON)
+}
+
+func (t *FacilitySvc) importData(
+	ctx context.Context,
+	r io.Reader,
+	read func(io.Reader) ([]models.Facility, *ImportReport, error),
+) (*SyncReport, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, errs.Err(err)
+	}
+	facilities, importReport, err := read(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidArgument, err)
+	}
+	if len(facilities) == 0 {
+		return nil, fmt.Errorf("%w: no valid facility in %d rows, %v", ErrInvalidArgument, importReport.Rows, importReport)
+	}
+	return t.apply(ctx, &Dataset{Version: contentHash(data), Facilities: facilities, Report: importReport}, false)
+}
+
+// apply writes the difference of the dataset to the stores, full rewrites unchanged facilities too
+func (t *FacilitySvc) apply(ctx context.Context, dataset *Dataset, full bool) (*SyncReport, error) {
+	report := &SyncReport{Version: dataset.Version, Import: dataset.Report}
+	facilities := dataset.Facilities
+	t.syncMu.Lock()
+	defer t.syncMu.Unlock()
+
+	state, err := t.getDatasetState(ctx)
+	if err != nil {
+		return report, errs.Errf("failed to get dataset state, %w", err)
+	}
+	if !full && state.Version == report.Version {
+		report.Unchanged = true
+		t.rebuild(facilities)
+		t.setRevision(state.Hashes)
+		return report, nil
+	}
+
+	// stored facilities unknown to any dataset state are taken as changed, so they are rewritten or removed
+	reconcile := state.Version == ""
+	if reconcile {
+		if state.Hashes, err = t.storedHashes(ctx); err != nil {
+			return report, errs.Errf("failed to list stored facilities, %w", err)
+		}
+	}
+
+	diff, err := diffFacilities(state.Hashes, facilities, full)
+	if err != nil {
+		return report, errs.Err(err)
+	}
+	if err = t.applyDiff(ctx, diff); err != nil {
+		return report, errs.Errf("failed to apply dataset, %w", err)
+	}
+	if reconcile {
+		if err = t.removeStale(ctx, facilities, diff.hashes); err != nil {
+			return report, errs.Errf("failed to remove stale members, %w", err)
+		}
+	}
+	t.rebuild(facilities)
+	t.setRevision(diff.hashes)
+	report.Inserted, report.Updated, report.Deleted = len(diff.inserted), len(diff.updated), len(diff.removed)
+	return report, t.DatasetStore.Set(ctx, []DatasetState{{
+		Name:      facilityDataset,
+		Version:   report.Version,
+		AppliedAt: time.Now(),
+		Hashes:    diff.hashes,
+	}})
+}
+
+// rebuild replaces state derived from all facilities, queries use the previous state until it is swapped
+func (t *FacilitySvc) rebuild(facilities []models.Facility) {
+	searchIndex := search.NewIndex[string]()
+	foodItems := search.NewSuggester()
+	for _, facility := range facilities {
+		searchIndex.Add(facility.LocationID, facility.FoodItems...)
+		foodItems.Add(facility.FoodItems...)
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.searchIndex, t.foodItems = searchIndex, foodItems
+	t.setLocations(facilities)
+}
+
+func (t *FacilitySvc) getDatasetState(ctx context.Context) (DatasetState, error) {
+	states, err := t.DatasetStore.Get(ctx, []string{facilityDataset})
+	// a store may return zero values for missing keys
+	if err != nil || len(states) == 0 {
+		return DatasetState{}, err
+	}
+	return states[0], nil
+}
+
+// storedHashes has an empty hash by LocationID of every stored facility, which no facility hashes to
+func (t *FacilitySvc) storedHashes(ctx context.Context) (map[string]string, error) {
+	ids, err := t.FacilityStore.Keys(ctx)
+	if err != nil {
+		return nil, err
+	}
+	hashes := make(map[string]string, len(ids))
+	for _, id := range ids {
+		hashes[id] = ""
+	}
+	return hashes, nil
+}
+
+// removeStale removes geo members and item slices of no facility in the dataset, e.g. slices of case-sensitive items
+func (t *FacilitySvc) removeStale(ctx context.Context, facilities []models.Facility, hashes map[string]string) error {
+	geoKeys, err := t.GeoFacilityStore.Keys(ctx)
+	if err != nil {
+		return err
+	}
+	var staleKeys []string
+	for _, key := range geoKeys {
+		if _, ok := hashes[key]; !ok {
+			staleKeys = append(staleKeys, key)
+		}
+	}
+	if len(staleKeys) > 0 {
+		if err = t.GeoFacilityStore.Del(ctx, staleKeys...); err != nil {
+			return err
+		}
+	}
+
+	items := make(map[string]bool)
+	for _, facility := range facilities {
+		for _, item := range facility.FoodItems {
+			items[models.FoodItemKey(item)] = true
+		}
+	}
+	sliceIDs, err := t.ItemFacilityStore.SliceIDs(ctx)
+	if err != nil {
+		return err
+	}
+	for _, id := range sliceIDs {
+		if !items[id] {
+			if err = t.ItemFacilityStore.DelSlice(ctx, id); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func hashFacility(facility models.Facility) (string, error) {
+	bs, err := json.Marshal(facility)
+	if err != nil {
+		return "", err
+	}
+	return contentHash(bs), nil
+}
+
+// diffFacilities compares facilities with the hashes last applied, full treats unchanged facilities as updated
+func diffFacilities(applied map[string]string, facilities []models.Facility, full bool) (facilityDiff, error) {
+	diff := facilityDiff{hashes: make(map[string]string, len(facilities))}
+	for _, facility := range facilities {
+		hash, err := hashFacility(facility)
+		if err != nil {
+			return diff, err
+		}
+		diff.hashes[facility.LocationID] = hash
+		switch old, ok := applied[facility.LocationID]; {
+		case !ok:
+			diff.inserted = append(diff.inserted, facility)
+		case old != hash || full:
+			diff.updated = append(diff.updated, facility)
+		}
+	}
+	for id := range applied {
+		if _, ok := diff.hashes[id]; !ok {
+			diff.removed = append(diff.removed, id)
+		}
+	}
+	return diff, nil
+}
+
+func (t *FacilitySvc) applyDiff(ctx context.Context, diff facilityDiff) error {
+	// previous versions tell which item slices a facility leaves
+	var oldIDs []string
+	for _, facility := range diff.updated {
+		oldIDs = append(oldIDs, facility.LocationID)
+	}
+	oldIDs = append(oldIDs, diff.removed...)
+	oldItems := make(map[string][]string, len(oldIDs))
+	if len(oldIDs) > 0 {
+		oldFacilities, err := t.FacilityStore.Get(ctx, oldIDs)
+		if err != nil {
+			return err
+		}
+		for _, facility := range oldFacilities {
+			if facility.LocationID != "" {
+				oldItems[facility.LocationID] = facility.FoodItems
+			}
+		}
+	}
+
+	upserted := append(diff.inserted[:len(diff.inserted):len(diff.inserted)], diff.updated...)
+	if err := t.cacheFacilities(ctx, upserted); err != nil {
+		return err
+	}
+	for _, facility := range diff.updated {
+		if err := t.removeFoodItems(ctx, facility.LocationID, oldItems[facility.LocationID], facility.FoodItems); err != nil {
+			return err
+		}
+	}
+	if err := t.cacheFoodItems(ctx, upserted); err != nil {
+		return err
+	}
+	if err := t.cacheLocations(ctx, upserted); err != nil {
+		return err
+	}
+
+	if len(diff.removed) == 0 {
+		return nil
+	}
+	for _, id := range diff.removed {
+		if err := t.removeFoodItems(ctx, id, oldItems[id], nil); err != nil {
+			return err
+		}
+	}
+	if err := t.GeoFacilityStore.Del(ctx, diff.removed...); err != nil {
+		return err
+	}
+	return t.FacilityStore.Del(ctx, diff.removed...)
+}
+
+// removeFoodItems removes the facility from slices of old items it no longer serves
+func (t *FacilitySvc) removeFoodItems(ctx context.Context, id string, oldItems []string, items []string) error {
+	keep := make(map[string]bool, len(items))
+	for _, item := range items {
+		keep[models.FoodItemKey(item)] = true
+	}
+	for _, item := range oldItems {
+		if key := models.FoodItemKey(item); !keep[key] {
+			if err := t.ItemFacilityStore.DelMember(ctx, key, []string{id}); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
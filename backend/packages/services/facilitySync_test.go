@@ -0,0 +1,175 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"food-trucks/packages/models"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func writeCSV(t *testing.T, p string, rows string) {
+	header := "locationid,Applicant,FoodItems,Latitude,Longitude\n"
+	if err := os.WriteFile(p, []byte(header+rows), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestFacilitySvc_Sync(t *testing.T) {
+	ctx := context.Background()
+	p := filepath.Join(t.TempDir(), "data.csv")
+	svc := newTestSvc()
+
+	writeCSV(t, p, `1,Taqueria,Tacos: Burritos,37.7955,-122.3937
+2,Cold Truck,Sandwiches,37.7956,-122.3938
+3,Pizza,Pizza,37.7957,-122.3939
+`)
+	report, err := svc.Sync(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Unchanged || report.Inserted != 3 || report.Updated != 0 || report.Deleted != 0 {
+		t.Fatalf("unexpected first sync %v", report)
+	}
+
+	report, err = svc.Sync(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !report.Unchanged {
+		t.Fatalf("expect same file unchanged, got %v", report)
+	}
+
+	// 1 stops serving burritos, 3 is gone, 4 is new
+	writeCSV(t, p, `1,Taqueria,Tacos,37.7955,-122.3937
+2,Cold Truck,Sandwiches,37.7956,-122.3938
+4,Burger,Burgers,37.7958,-122.3940
+`)
+	report, err = svc.Sync(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Unchanged || report.Inserted != 1 || report.Updated != 1 || report.Deleted != 1 {
+		t.Fatalf("unexpected second sync %v", report)
+	}
+
+	if items, _, _ := svc.GetByItem(ctx, "Burritos", Filter{}, Page{}); len(items) != 0 {
+		t.Fatalf("expect stale item member removed, got %v", items)
+	}
+	if items, _, _ := svc.GetByItem(ctx, "Tacos", Filter{}, Page{}); len(items) != 1 {
+		t.Fatalf("expect tacos kept, got %v", items)
+	}
+	nearest, err := svc.GetNearest(ctx, 37.7955, -122.3937, 10, 0, Filter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(nearest) != 3 {
+		t.Fatalf("expect removed facility out of geo index, got %v", nearest)
+	}
+	if _, err := svc.GetByID(ctx, "3"); !errors.Is(err, ErrNotFound) {
+		t.Fatal("expect removed facility deleted")
+	}
+	if results, _ := svc.Search(ctx, "pizza", 0, Filter{}); len(results) != 0 {
+		t.Fatalf("expect search index rebuilt, got %v", results)
+	}
+}
+
+func TestFacilitySvc_SyncWithoutState(t *testing.T) {
+	ctx := context.Background()
+	p := filepath.Join(t.TempDir(), "data.csv")
+	svc := newTestSvc()
+
+	// written by an older version without dataset state, slices keyed by the item as is
+	stored := []models.Facility{
+		{LocationID: "1", Applicant: "Taqueria", FoodItems: []string{"Tacos"}, Latitude: 37.7955, Longitude: -122.3937},
+		{LocationID: "3", Applicant: "Pizza", FoodItems: []string{"Pizza"}, Latitude: 37.7957, Longitude: -122.3939},
+	}
+	if err := svc.FacilityStore.Set(ctx, stored); err != nil {
+		t.Fatal(err)
+	}
+	for _, facility := range stored {
+		if err := svc.ItemFacilityStore.AddMem(ctx, facility.FoodItems[0], []models.Facility{facility}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// a member left without its facility
+	orphan := models.Facility{LocationID: "9", Latitude: 37.79, Longitude: -122.39}
+	if err := svc.GeoFacilityStore.AddAll(ctx, append(stored, orphan)); err != nil {
+		t.Fatal(err)
+	}
+
+	writeCSV(t, p, `1,Taqueria,Tacos,37.7955,-122.3937
+2,Cold Truck,Sandwiches,37.7956,-122.3938
+`)
+	report, err := svc.Sync(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Inserted != 1 || report.Updated != 1 || report.Deleted != 1 {
+		t.Fatalf("expect stored facilities reconciled, got %v", report)
+	}
+	if _, err := svc.GetByID(ctx, "3"); !errors.Is(err, ErrNotFound) {
+		t.Fatal("expect facility missing in the csv deleted")
+	}
+	geoKeys, err := svc.GeoFacilityStore.Keys(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sort.Strings(geoKeys); !reflect.DeepEqual(geoKeys, []string{"1", "2"}) {
+		t.Fatalf("expect geo members of the csv only, got %v", geoKeys)
+	}
+	sliceIDs, err := svc.ItemFacilityStore.SliceIDs(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sort.Strings(sliceIDs); !reflect.DeepEqual(sliceIDs, []string{"sandwiches", "tacos"}) {
+		t.Fatalf("expect item slices of the csv only, got %v", sliceIDs)
+	}
+	if items, _, _ := svc.GetByItem(ctx, "tacos", Filter{}, Page{}); len(items) != 1 {
+		t.Fatalf("expect tacos kept, got %v", items)
+	}
+
+	report, err = svc.Sync(p)
+	if err != nil || !report.Unchanged {
+		t.Fatalf("expect the next sync to use the state, got %v %v", report, err)
+	}
+}
+
+func TestFacilitySvc_Import(t *testing.T) {
+	ctx := context.Background()
+	svc := newTestSvc()
+	report, err := svc.ImportCSV(ctx, strings.NewReader(`locationid,FoodItems,Latitude,Longitude
+1,Tacos,37.7955,-122.3937
+2,Pizza,37.7956,-122.3938
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Inserted != 2 {
+		t.Fatalf("unexpected csv import %v", report)
+	}
+
+	report, err = svc.ImportJSON(ctx, strings.NewReader(`[{"locationID":"1","foodItems":"Tacos: Burritos","latitude":37.7955,"longitude":-122.3937}]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Inserted != 0 || report.Updated != 1 || report.Deleted != 1 {
+		t.Fatalf("unexpected json import %v", report)
+	}
+	if items, _, _ := svc.GetByItem(ctx, "burritos", Filter{}, Page{}); len(items) != 1 {
+		t.Fatalf("expect updated facility to serve burritos, got %v", items)
+	}
+
+	for _, invalid := range []string{`[]`, `[{"locationID":"1"}]`, `{`} {
+		if _, err = svc.ImportJSON(ctx, strings.NewReader(invalid)); !errors.Is(err, ErrInvalidArgument) {
+			t.Fatalf("expect %s to be invalid, got %v", invalid, err)
+		}
+	}
+	if _, err = svc.ImportCSV(ctx, strings.NewReader("Applicant\nTaqueria\n")); !errors.Is(err, ErrInvalidArgument) {
+		t.Fatalf("expect missing columns to be invalid, got %v", err)
+	}
+}
@@ -0,0 +1,140 @@
+package services
+
+import (
+	"fmt"
+	"food-trucks/packages/models"
+	"food-trucks/packages/util/errs"
+	"strings"
+	"time"
+)
+
+// Filter narrows facilities returned by queries, the zero value keeps everything
+type Filter struct {
+	// Statuses keeps facilities whose permit status is one of them
+	Statuses []models.PermitStatus
+	// ActiveAt keeps facilities whose permit is approved and not expired at this date
+	ActiveAt time.Time
+	// OpenAt keeps facilities whose dayshours include this instant, facilities without dayshours are dropped
+	OpenAt time.Time
+}
+
+// openAtLayouts are tried in order, times without zone are in models.ScheduleLocation
+var openAtLayouts = []string{time.RFC3339, "2006-01-02T15:04:05", "2006-01-02T15:04"}
+
+/*
+ParseFilter parses comma separated statuses (e.g. "APPROVED,ISSUED"),
+a date (e.g. "2022-01-31" or "today") for ActiveAt and
+a time (e.g. "2022-01-31T12:30" in San Francisco time, or "now") for OpenAt, empty strings disable the filter.
+It fails with errs.InvalidFields listing every invalid one of status, active_at and open_at.
+*/
+func ParseFilter(statuses string, activeAt string, openAt string) (Filter, error) {
+	var filter Filter
+	var invalid []errs.FieldError
+	for _, s := range strings.Split(statuses, ",") {
+		if strings.TrimSpace(s) == "" {
+			continue
+		}
+		status, err := models.ParsePermitStatus(s)
+		if err != nil {
+			names := make([]string, len(models.PermitStatuses))
+			for i, status := range models.PermitStatuses {
+				names[i] = string(status)
+			}
+			invalid = append(invalid, errs.FieldError{
+				Field:  "status",
+				Reason: fmt.Sprintf("%v, should be one of %s", err, strings.Join(names, ", ")),
+			})
+			break
+		}
+		filter.Statuses = append(filter.Statuses, status)
+	}
+
+	switch activeAt = strings.TrimSpace(activeAt); activeAt {
+	case "":
+	case "today":
+		filter.ActiveAt = time.Now()
+	default:
+		date, err := models.ParseDate(activeAt)
+		if err != nil {
+			invalid = append(invalid, errs.FieldError{Field: "active_at", Reason: fmt.Sprintf("%v, should be like 2022-01-31 or today", err)})
+		}
+		filter.ActiveAt = date
+	}
+
+	switch openAt = strings.TrimSpace(openAt); openAt {
+	case "":
+	case "now":
+		filter.OpenAt = time.Now()
+	default:
+		t, err := parseOpenAt(openAt)
+		if err != nil {
+			invalid = append(invalid, errs.FieldError{Field: "open_at", Reason: fmt.Sprintf("%v, should be like 2022-01-31T12:30 or now", err)})
+		}
+		filter.OpenAt = t
+	}
+	if len(invalid) > 0 {
+		return filter, errs.InvalidFields(invalid...)
+	}
+	return filter, nil
+}
+
+func parseOpenAt(s string) (time.Time, error) {
+	for _, layout := range openAtLayouts {
+		if t, err := time.ParseInLocation(layout, s, models.ScheduleLocation); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("%q is not a time", s)
+}
+
+func (f Filter) IsEmpty() bool {
+	return len(f.Statuses) == 0 && f.ActiveAt.IsZero() && f.OpenAt.IsZero()
+}
+
+func (f Filter) Match(facility models.Facility) bool {
+	if len(f.Statuses) > 0 && !f.matchStatus(facility.Status) {
+		return false
+	}
+	if !f.ActiveAt.IsZero() && !f.isActive(facility) {
+		return false
+	}
+	if !f.OpenAt.IsZero() && !facility.Hours.IsOpen(f.OpenAt) {
+		return false
+	}
+	return true
+}
+
+func (f Filter) Apply(facilities []models.Facility) []models.Facility {
+	if f.IsEmpty() {
+		return facilities
+	}
+	ret := make([]models.Facility, 0, len(facilities))
+	for _, facility := range facilities {
+		if f.Match(facility) {
+			ret = append(ret, facility)
+		}
+	}
+	return ret
+}
+
+func (f Filter) matchStatus(status models.PermitStatus) bool {
+	for _, s := range f.Statuses {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
+
+// isActive compares dates only, a permit expiring on 11/15/2022 is still active that day
+func (f Filter) isActive(facility models.Facility) bool {
+	day := dateOf(f.ActiveAt)
+	if facility.ExpirationDate.IsZero() || dateOf(facility.ExpirationDate).Before(day) {
+		return false
+	}
+	return facility.Approved.IsZero() || !dateOf(facility.Approved).After(day)
+}
+
+func dateOf(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,95 @@
+package services
+
+import (
+	"context"
+	"food-trucks/packages/models"
+	"food-trucks/packages/util/errs"
+	"testing"
+	"time"
+)
+
+func TestParseFilter(t *testing.T) {
+	filter, err := ParseFilter("approved, ISSUED,", "2022-01-31", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(filter.Statuses) != 2 || filter.Statuses[0] != models.PermitApproved || filter.ActiveAt.Day() != 31 {
+		t.Fatalf("unexpected filter %+v", filter)
+	}
+	_, err = ParseFilter("CLOSED", "someday", "")
+	if fields := errs.FieldsOf(err); errs.KindOf(err) != errs.KindInvalid || len(fields) != 2 ||
+		fields[0].Field != "status" || fields[1].Field != "active_at" {
+		t.Fatalf("expect status and active_at invalid, got %v %+v", err, fields)
+	}
+
+	filter, err = ParseFilter("", "", "2022-01-31T12:30")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if filter.OpenAt.In(time.UTC).Hour() != 20 {
+		t.Fatalf("expect open_at in San Francisco time, got %v", filter.OpenAt)
+	}
+	_, err = ParseFilter("", "", "noon")
+	if fields := errs.FieldsOf(err); len(fields) != 1 || fields[0].Field != "open_at" {
+		t.Fatalf("expect open_at invalid, got %v %+v", err, fields)
+	}
+}
+
+func TestFilter_Match(t *testing.T) {
+	facility := models.Facility{
+		Status:         models.PermitApproved,
+		Approved:       time.Date(2021, 11, 5, 0, 0, 0, 0, time.UTC),
+		ExpirationDate: time.Date(2022, 11, 15, 0, 0, 0, 0, time.UTC),
+	}
+	cases := []struct {
+		filter Filter
+		expect bool
+	}{
+		{Filter{}, true},
+		{Filter{Statuses: []models.PermitStatus{models.PermitExpired}}, false},
+		{Filter{ActiveAt: time.Date(2022, 11, 15, 18, 0, 0, 0, time.UTC)}, true},
+		{Filter{ActiveAt: time.Date(2022, 11, 16, 0, 0, 0, 0, time.UTC)}, false},
+		{Filter{ActiveAt: time.Date(2021, 11, 4, 0, 0, 0, 0, time.UTC)}, false},
+	}
+	for i, c := range cases {
+		if got := c.filter.Match(facility); got != c.expect {
+			t.Fatalf("case %d expect %v, got %v", i, c.expect, got)
+		}
+	}
+}
+
+func TestFilter_MatchOpenAt(t *testing.T) {
+	hours, err := models.ParseWeeklySchedule("Mo-Fr:7AM-8AM")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 2022-01-31 is a Monday
+	open := time.Date(2022, 1, 31, 7, 30, 0, 0, models.ScheduleLocation)
+	filter := Filter{OpenAt: open}
+	if !filter.Match(models.Facility{Hours: hours}) {
+		t.Fatal("expect open on monday morning")
+	}
+	if filter.Match(models.Facility{}) {
+		t.Fatal("expect unknown schedule to be filtered out")
+	}
+	if (Filter{OpenAt: open.Add(time.Hour)}).Match(models.Facility{Hours: hours}) {
+		t.Fatal("expect closed after 8AM")
+	}
+}
+
+func TestFacilitySvc_GetByLocationFiltered(t *testing.T) {
+	svc := mustInit()
+	filter := Filter{Statuses: []models.PermitStatus{models.PermitApproved, models.PermitIssued}}
+	items, _, err := svc.GetByLocation(context.Background(), 37.7955, -122.3937, 2, filter, Page{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) == 0 {
+		t.Fatal("expect approved facilities downtown")
+	}
+	for _, item := range items {
+		if !filter.Match(item) {
+			t.Fatalf("unexpected status %v", item.Status)
+		}
+	}
+}
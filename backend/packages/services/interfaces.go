@@ -8,14 +8,37 @@ import (
 type FacilityStore interface {
 	Set(ctx context.Context, vals []models.Facility) error
 	Get(ctx context.Context, keys []string) ([]models.Facility, error)
+	Del(ctx context.Context, ids ...string) error
+	Keys(ctx context.Context) ([]string, error)
 }
 
 type ItemFacilityStore interface {
 	AddMem(ctx context.Context, sliceID any, items []models.Facility) error
+	AddMembers(ctx context.Context, members map[any][]models.Facility) error
+	DelMember(ctx context.Context, sliceID any, memberKeys []string) error
+	DelSlice(ctx context.Context, sliceID any) error
+	SliceIDs(ctx context.Context) ([]string, error)
 	GetAllMemberEntities(ctx context.Context, sliceID any) ([]models.Facility, error)
+	GetMemberEntities(ctx context.Context, sliceID any, offset int, count int) ([]models.Facility, error)
 }
 
 type GeoFacilityStore interface {
 	Add(ctx context.Context, item models.Facility) error
+	AddAll(ctx context.Context, items []models.Facility) error
+	Del(ctx context.Context, ids ...string) error
+	Keys(ctx context.Context) ([]string, error)
 	Get(ctx context.Context, lat float64, lon float64, radius float64) ([]models.Facility, error)
+	GetNearest(ctx context.Context, lat float64, lon float64, radius float64, count int) ([]models.Facility, []float64, error)
+	GetNearestInSlice(ctx context.Context, sliceID any, lat float64, lon float64, radius float64, count int) ([]models.Facility, []float64, error)
+	GetInBox(ctx context.Context, minLat, minLon, maxLat, maxLon float64, count int) ([]models.Facility, error)
+}
+
+type DatasetStore interface {
+	Set(ctx context.Context, vals []DatasetState) error
+	Get(ctx context.Context, keys []string) ([]DatasetState, error)
+}
+
+// TileStore caches encoded tiles, fetch encodes the tiles missing and they are set to the store
+type TileStore interface {
+	GetFetchSet(ctx context.Context, keys []string, fetch func([]string) ([]Tile, error)) ([]Tile, error, error)
 }
@@ -0,0 +1,24 @@
+package services
+
+import (
+	"math"
+)
+
+// tileSize is the pixel size of a web mercator tile, the world is tileSize * 2^zoom pixels wide at a zoom
+const tileSize = 256
+
+// MaxZoom is the deepest zoom level supported by map queries
+const MaxZoom = 22
+
+// maxMercatorLat is where web mercator is cut off, the world becomes a square
+const maxMercatorLat = 85.05112878
+
+// mercatorPixel projects lat/lon to global pixel coordinates at zoom, y grows southward
+func mercatorPixel(lat, lon float64, zoom int) (float64, float64) {
+	lat = math.Max(math.Min(lat, maxMercatorLat), -maxMercatorLat)
+	scale := tileSize * math.Exp2(float64(zoom))
+	x := (lon + 180) / 360 * scale
+	sin := math.Sin(lat * math.Pi / 180)
+	y := (0.5 - math.Log((1+sin)/(1-sin))/(4*math.Pi)) * scale
+	return x, y
+}
@@ -0,0 +1,45 @@
+package services
+
+import "food-trucks/packages/models"
+
+// Page selects Limit facilities after Offset of a list, Limit <= 0 means all after Offset
+type Page struct {
+	Offset int
+	Limit  int
+}
+
+/*
+paginate returns the facilities of page and the page after it, nil if there is none.
+fetch gets count facilities from offset of the unfiltered list, count <= 0 means all after offset.
+Without filter the page is fetched from the store, with one the whole list is, as filters are applied in process.
+*/
+func paginate(page Page, filter Filter, fetch func(offset int, count int) ([]models.Facility, error)) ([]models.Facility, *Page, error) {
+	page.Offset = max(page.Offset, 0)
+	var facilities []models.Facility
+	var err error
+	if filter.IsEmpty() {
+		count := 0
+		if page.Limit > 0 {
+			// one more tells if there is a next page
+			count = page.Limit + 1
+		}
+		if facilities, err = fetch(page.Offset, count); err != nil {
+			return nil, nil, err
+		}
+	} else {
+		if facilities, err = fetch(0, 0); err != nil {
+			return nil, nil, err
+		}
+		facilities = filter.Apply(facilities)
+		facilities = facilities[min(page.Offset, len(facilities)):]
+	}
+	if page.Limit > 0 && len(facilities) > page.Limit {
+		return facilities[:page.Limit], &Page{Offset: page.Offset + page.Limit, Limit: page.Limit}, nil
+	}
+	return facilities, nil, nil
+}
+
+// skip drops the first offset facilities of a store which can only limit how many it returns
+func skip(facilities []models.Facility, offset int) []models.Facility {
+	return facilities[min(offset, len(facilities)):]
+}
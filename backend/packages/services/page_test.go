@@ -0,0 +1,65 @@
+package services
+
+import (
+	"context"
+	"testing"
+)
+
+func TestFacilitySvc_GetByItemPaged(t *testing.T) {
+	ctx := context.Background()
+	svc := mustInit()
+	all, _, err := svc.GetByItem(ctx, "noodles", Filter{}, Page{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var paged []string
+	page := &Page{Limit: 7}
+	for page != nil {
+		facilities, next, err := svc.GetByItem(ctx, "noodles", Filter{}, *page)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(facilities) > 7 || len(facilities) == 0 {
+			t.Fatalf("unexpected page size %d of %v", len(facilities), page)
+		}
+		for _, facility := range facilities {
+			paged = append(paged, facility.LocationID)
+		}
+		page = next
+	}
+	if len(paged) != len(all) {
+		t.Fatalf("expect %d facilities in pages, got %d", len(all), len(paged))
+	}
+	for i := range all {
+		if paged[i] != all[i].LocationID {
+			t.Fatalf("expect pages in list order, %s at %d is %s", paged[i], i, all[i].LocationID)
+		}
+	}
+}
+
+func TestFacilitySvc_GetByLocationPagedFiltered(t *testing.T) {
+	ctx := context.Background()
+	svc := mustInit()
+	filter, err := ParseFilter("APPROVED", "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	all, _, err := svc.GetByLocation(ctx, 37.7955, -122.3937, 2, filter, Page{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(all) < 3 {
+		t.Fatalf("expect approved facilities near the ferry building, got %v", all)
+	}
+	items, next, err := svc.GetByLocation(ctx, 37.7955, -122.3937, 2, filter, Page{Offset: 1, Limit: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 2 || items[0].LocationID != all[1].LocationID || items[1].LocationID != all[2].LocationID {
+		t.Fatalf("expect the second and third approved facilities, got %v", items)
+	}
+	if (next == nil) != (len(all) == 3) || next != nil && next.Offset != 3 {
+		t.Fatalf("unexpected next page %v of %d", next, len(all))
+	}
+}
@@ -0,0 +1,138 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+const (
+	RefreshOK          = "ok"
+	RefreshNotModified = "not modified"
+	RefreshFailed      = "failed"
+)
+
+// RefreshStatus tells how fresh the served dataset is
+type RefreshStatus struct {
+	Source string `json:"source"`
+	// Interval is between checks of the source, empty if refreshing is off
+	Interval string `json:"interval,omitempty"`
+	// LastCheck is when the source was last checked, successful or not
+	LastCheck time.Time `json:"lastCheck"`
+	// LastRefresh is when a dataset was last loaded and applied
+	LastRefresh time.Time `json:"lastRefresh"`
+	// Status is the result of the last check, "ok", "not modified" or "failed"
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+	// Report is of the last applied dataset
+	Report *SyncReport `json:"report,omitempty"`
+}
+
+/*
+Refresher checks the source periodically and applies its changes through the service.
+Sources skip loading when unchanged since the last load, see FacilitySource.
+*/
+type Refresher struct {
+	svc      *FacilitySvc
+	source   FacilitySource
+	interval time.Duration
+
+	mu     sync.RWMutex
+	status RefreshStatus
+	// since is the validator of the last applied load
+	since string
+}
+
+func NewRefresher(svc *FacilitySvc, source FacilitySource, interval time.Duration) *Refresher {
+	r := &Refresher{svc: svc, source: source, interval: interval}
+	r.status.Source = fmt.Sprint(source)
+	if interval > 0 {
+		r.status.Interval = interval.String()
+	}
+	return r
+}
+
+// Refresh checks the source once, the report is nil if the source is not modified.
+// A failed check is retried by the next one.
+func (r *Refresher) Refresh(ctx context.Context) (*SyncReport, error) {
+	r.mu.RLock()
+	since := r.since
+	r.mu.RUnlock()
+
+	dataset, err := r.source.Load(ctx, since)
+	var report *SyncReport
+	if err == nil {
+		report, err = r.svc.ApplyDataset(ctx, dataset)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.status.LastCheck = time.Now()
+	r.status.Error = ""
+	switch {
+	case errors.Is(err, ErrNotModified):
+		r.status.Status = RefreshNotModified
+		return nil, nil
+	case err != nil:
+		r.status.Status = RefreshFailed
+		r.status.Error = err.Error()
+		return nil, err
+	}
+	r.since = dataset.Validator
+	r.status.Status = RefreshOK
+	r.status.LastRefresh = r.status.LastCheck
+	r.status.Report = report
+	return report, nil
+}
+
+// Logger is where the refresher reports, the web app passes its iris logger
+type Logger interface {
+	Infof(format string, args ...any)
+	Errorf(format string, args ...any)
+}
+
+/*
+Start refreshes every interval until ctx is done or the returned stop is called,
+stop waits for a running refresh to return. It does nothing if interval is not positive.
+*/
+func (r *Refresher) Start(ctx context.Context, logger Logger) (stop func()) {
+	if r.interval <= 0 {
+		return func() {}
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(r.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				report, err := r.Refresh(ctx)
+				switch {
+				case ctx.Err() != nil:
+					// stopped while refreshing
+					return
+				case err != nil:
+					logger.Errorf("refresh: %v", err)
+				case report != nil && !report.Unchanged:
+					logger.Infof("refresh: %v", report)
+				}
+			}
+		}
+	}()
+	return func() {
+		cancel()
+		<-done
+	}
+}
+
+func (r *Refresher) Status() RefreshStatus {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.status
+}
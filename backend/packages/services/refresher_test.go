@@ -0,0 +1,101 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRefresher_Refresh(t *testing.T) {
+	ctx := context.Background()
+	p := filepath.Join(t.TempDir(), "data.csv")
+	writeCSV(t, p, `1,Taqueria,Tacos,37.0,-122.0
+`)
+	svc := newTestSvc()
+	refresher := NewRefresher(svc, NewCSVFacilitySource(p), time.Minute)
+
+	report, err := refresher.Refresh(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report == nil || report.Inserted != 1 || svc.GetCenter().Lat != 37 {
+		t.Fatalf("unexpected first refresh %v, center %v", report, svc.GetCenter())
+	}
+	refreshed := refresher.Status().LastRefresh
+
+	if report, err = refresher.Refresh(ctx); err != nil || report != nil {
+		t.Fatalf("expect not modified, got %v %v", report, err)
+	}
+	if status := refresher.Status(); status.Status != RefreshNotModified || status.LastRefresh != refreshed ||
+		status.Report == nil || status.Interval != "1m0s" {
+		t.Fatalf("unexpected status %+v", status)
+	}
+
+	writeCSV(t, p, `1,Taqueria,Tacos,37.0,-122.0
+2,Pizza,Pizza,38.0,-122.0
+`)
+	// file systems with coarse mtime may not see the rewrite
+	later := time.Now().Add(time.Minute)
+	if err = os.Chtimes(p, later, later); err != nil {
+		t.Fatal(err)
+	}
+	if report, err = refresher.Refresh(ctx); err != nil || report == nil || report.Inserted != 1 {
+		t.Fatalf("unexpected refresh %v %v", report, err)
+	}
+	if svc.GetCenter().Lat != 37.5 {
+		t.Fatalf("expect center recomputed, got %v", svc.GetCenter())
+	}
+
+	if err = os.Remove(p); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = refresher.Refresh(ctx); err == nil {
+		t.Fatal("expect missing file to fail")
+	}
+	if status := refresher.Status(); status.Status != RefreshFailed || status.Error == "" || status.Report.Inserted != 1 {
+		t.Fatalf("expect failure kept with the last report, got %+v", status)
+	}
+}
+
+// chanLogger sends every message to its channel
+type chanLogger chan string
+
+func (l chanLogger) Infof(format string, args ...any) {
+	l <- fmt.Sprintf(format, args...)
+}
+
+func (l chanLogger) Errorf(format string, args ...any) {
+	l <- fmt.Sprintf("error "+format, args...)
+}
+
+func TestRefresher_Start(t *testing.T) {
+	p := filepath.Join(t.TempDir(), "data.csv")
+	writeCSV(t, p, `1,Taqueria,Tacos,37.0,-122.0
+`)
+	svc := newTestSvc()
+	refresher := NewRefresher(svc, NewCSVFacilitySource(p), 10*time.Millisecond)
+
+	logger := make(chanLogger, 10)
+	stop := refresher.Start(context.Background(), logger)
+	select {
+	case msg := <-logger:
+		if !strings.HasPrefix(msg, "refresh: ") {
+			t.Fatalf("unexpected log %q", msg)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("expect the refresh to be logged")
+	}
+	stop()
+	checked := refresher.Status().LastCheck
+	time.Sleep(50 * time.Millisecond)
+	if refresher.Status().LastCheck != checked {
+		t.Fatal("expect no refresh after stop")
+	}
+
+	// refreshing off
+	NewRefresher(svc, NewCSVFacilitySource(p), 0).Start(context.Background(), logger)()
+}
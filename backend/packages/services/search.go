@@ -0,0 +1,59 @@
+package services
+
+import (
+	"context"
+	"food-trucks/packages/models"
+	"food-trucks/packages/util/search"
+)
+
+// SearchResult is a facility with its relevance to the query, higher is better
+type SearchResult struct {
+	Facility models.Facility `json:"facility"`
+	Score    float64         `json:"score"`
+}
+
+/*
+Search finds facilities by food items, tolerating plurals, prefixes and typos,
+e.g. "taco", "burr" and "burito" all find "Burritos". Best matches come first, limit <= 0 means no limit.
+*/
+func (t *FacilitySvc) Search(ctx context.Context, query string, limit int, filter Filter) ([]SearchResult, error) {
+	t.mu.RLock()
+	searchIndex := t.searchIndex
+	t.mu.RUnlock()
+	hits := searchIndex.Search(query, 0)
+	if len(hits) == 0 {
+		return []SearchResult{}, nil
+	}
+	keys := make([]string, len(hits))
+	for i, hit := range hits {
+		keys[i] = hit.Key
+	}
+	facilities, err := t.FacilityStore.Get(ctx, keys)
+	if err != nil {
+		return nil, err
+	}
+	byKey := make(map[string]models.Facility, len(facilities))
+	for _, facility := range facilities {
+		byKey[facility.LocationID] = facility
+	}
+
+	ret := make([]SearchResult, 0, len(hits))
+	for _, hit := range hits {
+		if limit > 0 && len(ret) == limit {
+			break
+		}
+		facility, ok := byKey[hit.Key]
+		if ok && filter.Match(facility) {
+			ret = append(ret, SearchResult{Facility: facility, Score: hit.Score})
+		}
+	}
+	return ret, nil
+}
+
+// SuggestItems autocompletes food items, items served by more facilities come first
+func (t *FacilitySvc) SuggestItems(prefix string, limit int) []search.Suggestion {
+	t.mu.RLock()
+	foodItems := t.foodItems
+	t.mu.RUnlock()
+	return foodItems.Suggest(prefix, limit)
+}
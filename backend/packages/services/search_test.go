@@ -0,0 +1,63 @@
+package services
+
+import (
+	"context"
+	"food-trucks/packages/models"
+	"strings"
+	"testing"
+)
+
+func TestFacilitySvc_Search(t *testing.T) {
+	ctx := context.Background()
+	svc := mustInit()
+	cases := []struct {
+		query string
+		item  string
+	}{
+		{"Taco", "taco"},
+		{"burrito", "burrito"},
+		{"burito", "burrito"},
+		{"quesad", "quesadilla"},
+	}
+	for _, c := range cases {
+		results, err := svc.Search(ctx, c.query, 5, Filter{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(results) != 5 {
+			t.Fatalf("search %q expect 5 results, got %d", c.query, len(results))
+		}
+		for i, result := range results {
+			if !strings.Contains(strings.ToLower(models.JoinFoodItems(result.Facility.FoodItems)), c.item) {
+				t.Fatalf("search %q got unrelated %v", c.query, result.Facility.FoodItems)
+			}
+			if i > 0 && result.Score > results[i-1].Score {
+				t.Fatalf("search %q expect descending scores", c.query)
+			}
+		}
+	}
+
+	results, err := svc.Search(ctx, "tacos", 0, Filter{Statuses: []models.PermitStatus{models.PermitApproved}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, result := range results {
+		if result.Facility.Status != models.PermitApproved {
+			t.Fatalf("unexpected status %v", result.Facility.Status)
+		}
+	}
+}
+
+func TestFacilitySvc_SuggestItems(t *testing.T) {
+	svc := mustInit()
+	suggestions := svc.SuggestItems("bur", 3)
+	// burritos, Burritos, burrito and Burrito are one term, displayed as the most frequent form
+	if len(suggestions) != 3 || suggestions[0].Term != "burritos" || suggestions[0].Count <= 100 {
+		t.Fatalf("unexpected suggestions %v", suggestions)
+	}
+	for i, suggestion := range suggestions {
+		if i > 0 && suggestion.Count > suggestions[i-1].Count {
+			t.Fatalf("expect most popular first, got %v", suggestions)
+		}
+	}
+}
@@ -0,0 +1,170 @@
+package services
+
+import (
+	"context"
+	"food-trucks/packages/models"
+	"os"
+	"sync/atomic"
+	"testing"
+)
+
+/*
+counting stores count calls that cost a redis round trip with the rdb stores,
+rdb's AddMem costs two, it sets entities before adding members.
+*/
+type countingFacilityStore struct {
+	FacilityStore
+	trips *atomic.Int64
+}
+
+func (s countingFacilityStore) Set(ctx context.Context, vals []models.Facility) error {
+	s.trips.Add(1)
+	return s.FacilityStore.Set(ctx, vals)
+}
+
+func (s countingFacilityStore) Get(ctx context.Context, keys []string) ([]models.Facility, error) {
+	s.trips.Add(1)
+	return s.FacilityStore.Get(ctx, keys)
+}
+
+func (s countingFacilityStore) Keys(ctx context.Context) ([]string, error) {
+	s.trips.Add(1)
+	return s.FacilityStore.Keys(ctx)
+}
+
+type countingItemFacilityStore struct {
+	ItemFacilityStore
+	trips *atomic.Int64
+}
+
+func (s countingItemFacilityStore) AddMem(ctx context.Context, sliceID any, items []models.Facility) error {
+	s.trips.Add(2)
+	return s.ItemFacilityStore.AddMem(ctx, sliceID, items)
+}
+
+func (s countingItemFacilityStore) AddMembers(ctx context.Context, members map[any][]models.Facility) error {
+	s.trips.Add(1)
+	return s.ItemFacilityStore.AddMembers(ctx, members)
+}
+
+func (s countingItemFacilityStore) SliceIDs(ctx context.Context) ([]string, error) {
+	s.trips.Add(1)
+	return s.ItemFacilityStore.SliceIDs(ctx)
+}
+
+type countingGeoFacilityStore struct {
+	GeoFacilityStore
+	trips *atomic.Int64
+}
+
+func (s countingGeoFacilityStore) Add(ctx context.Context, item models.Facility) error {
+	s.trips.Add(1)
+	return s.GeoFacilityStore.Add(ctx, item)
+}
+
+func (s countingGeoFacilityStore) AddAll(ctx context.Context, items []models.Facility) error {
+	s.trips.Add(1)
+	return s.GeoFacilityStore.AddAll(ctx, items)
+}
+
+func (s countingGeoFacilityStore) Keys(ctx context.Context) ([]string, error) {
+	s.trips.Add(1)
+	return s.GeoFacilityStore.Keys(ctx)
+}
+
+type countingDatasetStore struct {
+	DatasetStore
+	trips *atomic.Int64
+}
+
+func (s countingDatasetStore) Set(ctx context.Context, vals []DatasetState) error {
+	s.trips.Add(1)
+	return s.DatasetStore.Set(ctx, vals)
+}
+
+func (s countingDatasetStore) Get(ctx context.Context, keys []string) ([]DatasetState, error) {
+	s.trips.Add(1)
+	return s.DatasetStore.Get(ctx, keys)
+}
+
+func newCountingSvc(trips *atomic.Int64) *FacilitySvc {
+	svc := newTestSvc()
+	return NewFacilitySvc(
+		countingFacilityStore{FacilityStore: svc.FacilityStore, trips: trips},
+		countingItemFacilityStore{ItemFacilityStore: svc.ItemFacilityStore, trips: trips},
+		countingGeoFacilityStore{GeoFacilityStore: svc.GeoFacilityStore, trips: trips},
+		countingDatasetStore{DatasetStore: svc.DatasetStore, trips: trips},
+	)
+}
+
+/*
+BenchmarkSeed compares round trips of writing the dataset one call per item and location with the batched writes
+of Seed, both time only the writes to fresh stores, reading and diffing the csv are done before.
+The in memory stores cost nothing per round trip, so ns/op only shows the overhead of batching.
+*/
+func BenchmarkSeed(b *testing.B) {
+	file, err := os.Open("../../configs/data.csv")
+	if err != nil {
+		b.Fatal(err)
+	}
+	facilities, _, err := ReadCSV(file)
+	file.Close()
+	if err != nil {
+		b.Fatal(err)
+	}
+	diff, err := diffFacilities(nil, facilities, false)
+	if err != nil {
+		b.Fatal(err)
+	}
+	ctx := context.Background()
+
+	b.Run("PerItem", func(b *testing.B) {
+		var trips atomic.Int64
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			b.StopTimer()
+			svc := newCountingSvc(&trips)
+			b.StartTimer()
+			if err := svc.FacilityStore.Set(ctx, facilities); err != nil {
+				b.Fatal(err)
+			}
+			for _, facility := range facilities {
+				for _, item := range facility.FoodItems {
+					if err := svc.ItemFacilityStore.AddMem(ctx, models.FoodItemKey(item), []models.Facility{facility}); err != nil {
+						b.Fatal(err)
+					}
+				}
+				if err := svc.GeoFacilityStore.Add(ctx, facility); err != nil {
+					b.Fatal(err)
+				}
+			}
+		}
+		b.ReportMetric(float64(trips.Load())/float64(b.N), "roundtrips/op")
+	})
+
+	b.Run("Batched", func(b *testing.B) {
+		var trips atomic.Int64
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			b.StopTimer()
+			svc := newCountingSvc(&trips)
+			b.StartTimer()
+			if err := svc.applyDiff(ctx, diff); err != nil {
+				b.Fatal(err)
+			}
+		}
+		b.ReportMetric(float64(trips.Load())/float64(b.N), "roundtrips/op")
+	})
+}
+
+func TestSeed_RoundTrips(t *testing.T) {
+	var trips atomic.Int64
+	if _, err := newCountingSvc(&trips).Seed("../../configs/data.csv"); err != nil {
+		t.Fatal(err)
+	}
+	// dataset state get and set, facilities, item members, locations,
+	// and without a state the stored facilities, geo members and item slices to reconcile
+	if trips.Load() != 8 {
+		t.Fatalf("expect 8 round trips to seed, got %d", trips.Load())
+	}
+}
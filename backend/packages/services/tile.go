@@ -0,0 +1,143 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"food-trucks/packages/models"
+	"food-trucks/packages/util/mvt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	// TileLayer is the name of the vector tile layer of facility points
+	TileLayer = "facilities"
+	// tileBufferPixels widens the box a tile is queried with, so markers on an edge are drawn by both tiles
+	tileBufferPixels = 8
+)
+
+// Tile is an encoded vector tile, Key has the dataset revision and z/x/y
+type Tile struct {
+	Key  string `json:"key"`
+	Data []byte `json:"data"`
+}
+
+func GetTileKey(tile Tile) string {
+	return tile.Key
+}
+
+// WithTileStore caches encoded tiles, without it every tile is encoded on request
+func (t *FacilitySvc) WithTileStore(store TileStore) *FacilitySvc {
+	t.TileStore = store
+	return t
+}
+
+/*
+GetTile returns the Mapbox Vector Tile of facilities in tile x, y at zoom z, an empty tile has no layer.
+Tiles are cached by the revision of the dataset, so a sync or an edit makes new tiles instead of serving stale ones.
+*/
+func (t *FacilitySvc) GetTile(ctx context.Context, z, x, y int) ([]byte, error) {
+	if z < 0 || z > MaxZoom {
+		return nil, fmt.Errorf("%w: zoom should be in [0, %d]", ErrInvalidArgument, MaxZoom)
+	}
+	if n := 1 << z; x < 0 || x >= n || y < 0 || y >= n {
+		return nil, fmt.Errorf("%w: tile %d/%d/%d is outside of the world", ErrNotFound, z, x, y)
+	}
+	if t.TileStore == nil {
+		return t.encodeTile(ctx, z, x, y)
+	}
+
+	t.mu.RLock()
+	key := fmt.Sprintf("%.16s/%d/%d/%d", t.revision, z, x, y)
+	t.mu.RUnlock()
+	// a tile failing to be cached is still served, the warning is dropped
+	tiles, err, _ := t.TileStore.GetFetchSet(ctx, []string{key}, func(keys []string) ([]Tile, error) {
+		data, err := t.encodeTile(ctx, z, x, y)
+		if err != nil {
+			return nil, err
+		}
+		return []Tile{{Key: key, Data: data}}, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(tiles) == 0 {
+		return nil, nil
+	}
+	return tiles[0].Data, nil
+}
+
+// encodeTile projects facilities inside the tile to tile units, properties are what a marker needs
+func (t *FacilitySvc) encodeTile(ctx context.Context, z, x, y int) ([]byte, error) {
+	facilities, _, err := t.GetByBox(ctx, tileBox(z, x, y), Filter{}, Page{})
+	if err != nil {
+		return nil, err
+	}
+	layer := mvt.NewLayer(TileLayer, mvt.DefaultExtent)
+	scale := float64(layer.Extent()) / tileSize
+	for _, facility := range facilities {
+		px, py := mercatorPixel(facility.Latitude, facility.Longitude, z)
+		// ids of data.csv are numeric, others are only in properties
+		id, _ := strconv.ParseUint(facility.LocationID, 10, 64)
+		err = layer.AddPoint(id,
+			int(math.Round((px-float64(x*tileSize))*scale)),
+			int(math.Round((py-float64(y*tileSize))*scale)),
+			map[string]any{
+				"locationID":   facility.LocationID,
+				"applicant":    facility.Applicant,
+				"facilityType": facility.FacilityType,
+				"status":       string(facility.Status),
+				"foodItems":    models.JoinFoodItems(facility.FoodItems),
+			})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return mvt.Encode(layer), nil
+}
+
+// tileBox is the lat/lon box of a tile widened by tileBufferPixels, clamped to the world
+func tileBox(z, x, y int) BBox {
+	minLon, maxLat := mercatorLatLon(float64(x*tileSize-tileBufferPixels), float64(y*tileSize-tileBufferPixels), z)
+	maxLon, minLat := mercatorLatLon(float64((x+1)*tileSize+tileBufferPixels), float64((y+1)*tileSize+tileBufferPixels), z)
+	return BBox{
+		MinLat: math.Max(minLat, -maxMercatorLat),
+		MinLon: math.Max(minLon, -180),
+		MaxLat: math.Min(maxLat, maxMercatorLat),
+		MaxLon: math.Min(maxLon, 180),
+	}
+}
+
+// mercatorLatLon is the inverse of mercatorPixel, it returns lon then lat like x then y
+func mercatorLatLon(x, y float64, zoom int) (float64, float64) {
+	scale := tileSize * math.Exp2(float64(zoom))
+	lon := x/scale*360 - 180
+	lat := math.Atan(math.Sinh(math.Pi*(1-2*y/scale))) * 180 / math.Pi
+	return lon, lat
+}
+
+// datasetRevision hashes the content hashes of all facilities, it only changes if a facility does
+func datasetRevision(hashes map[string]string) string {
+	ids := make([]string, 0, len(hashes))
+	for id := range hashes {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	var b strings.Builder
+	for _, id := range ids {
+		b.WriteString(id)
+		b.WriteByte(':')
+		b.WriteString(hashes[id])
+		b.WriteByte('\n')
+	}
+	return contentHash([]byte(b.String()))
+}
+
+func (t *FacilitySvc) setRevision(hashes map[string]string) {
+	revision := datasetRevision(hashes)
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.revision = revision
+}
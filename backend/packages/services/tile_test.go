@@ -0,0 +1,106 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"math"
+	"testing"
+)
+
+// fakeTileStore is a map of tiles which counts the tiles fetched
+type fakeTileStore struct {
+	tiles   map[string]Tile
+	fetched []string
+}
+
+func (s *fakeTileStore) GetFetchSet(ctx context.Context, keys []string, fetch func([]string) ([]Tile, error)) ([]Tile, error, error) {
+	var ret []Tile
+	var missed []string
+	for _, key := range keys {
+		if tile, ok := s.tiles[key]; ok {
+			ret = append(ret, tile)
+		} else {
+			missed = append(missed, key)
+		}
+	}
+	if len(missed) == 0 {
+		return ret, nil, nil
+	}
+	s.fetched = append(s.fetched, missed...)
+	tiles, err := fetch(missed)
+	if err != nil {
+		return nil, err, nil
+	}
+	for _, tile := range tiles {
+		s.tiles[tile.Key] = tile
+	}
+	return append(ret, tiles...), nil, nil
+}
+
+// tileOf is the tile x, y of a point at zoom
+func tileOf(lat, lon float64, zoom int) (int, int) {
+	px, py := mercatorPixel(lat, lon, zoom)
+	return int(math.Floor(px / tileSize)), int(math.Floor(py / tileSize))
+}
+
+func TestTileBox(t *testing.T) {
+	box := tileBox(0, 0, 0)
+	if box.MinLon != -180 || box.MaxLon != 180 || box.MaxLat != maxMercatorLat || box.MinLat != -maxMercatorLat {
+		t.Fatalf("expect the world clamped, got %+v", box)
+	}
+	x, y := tileOf(37.7955, -122.3937, 14)
+	if box = tileBox(14, x, y); !box.Contains(37.7955, -122.3937) || box.MaxLon-box.MinLon > 0.03 {
+		t.Fatalf("expect a small box around the ferry building, got %+v", box)
+	}
+}
+
+func TestFacilitySvc_GetTile(t *testing.T) {
+	ctx := context.Background()
+	store := &fakeTileStore{tiles: make(map[string]Tile)}
+	svc := mustInit().WithTileStore(store)
+	facilities, _, err := svc.GetByLocation(ctx, 37.7955, -122.3937, 1, Filter{}, Page{Limit: 1})
+	if err != nil || len(facilities) == 0 {
+		t.Fatalf("expect a facility near the ferry building, got %v %v", facilities, err)
+	}
+	facility := facilities[0]
+	x, y := tileOf(facility.Latitude, facility.Longitude, 15)
+
+	tile, err := svc.GetTile(ctx, 15, x, y)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Contains(tile, []byte(TileLayer)) || !bytes.Contains(tile, []byte(facility.Applicant)) {
+		t.Fatalf("expect %s in tile %d/%d/%d", facility.Applicant, 15, x, y)
+	}
+	cached, err := svc.GetTile(ctx, 15, x, y)
+	if err != nil || !bytes.Equal(cached, tile) || len(store.fetched) != 1 {
+		t.Fatalf("expect the same tile from the store, got %v, fetched %v", err, store.fetched)
+	}
+
+	if err = svc.DeleteFacility(ctx, facility.LocationID); err != nil {
+		t.Fatal(err)
+	}
+	tile, err = svc.GetTile(ctx, 15, x, y)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(tile, []byte(facility.LocationID)) || len(store.fetched) != 2 || store.fetched[0] == store.fetched[1] {
+		t.Fatalf("expect a new tile without deleted %s, fetched %v", facility.LocationID, store.fetched)
+	}
+}
+
+func TestFacilitySvc_GetTileInvalid(t *testing.T) {
+	ctx := context.Background()
+	svc := mustInit()
+	if _, err := svc.GetTile(ctx, 23, 0, 0); !errors.Is(err, ErrInvalidArgument) {
+		t.Fatalf("expect invalid zoom, got %v", err)
+	}
+	if _, err := svc.GetTile(ctx, 1, 2, 0); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expect tile outside of the world not found, got %v", err)
+	}
+	tile, err := svc.GetTile(ctx, 10, 0, 0)
+	if err != nil || len(tile) != 0 {
+		t.Fatalf("expect an empty tile in the pacific, got %d bytes %v", len(tile), err)
+	}
+}
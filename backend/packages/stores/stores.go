@@ -0,0 +1,63 @@
+package stores
+
+import (
+	"fmt"
+	"food-trucks/packages/models"
+	"food-trucks/packages/services"
+	"food-trucks/packages/util/memdb"
+	"food-trucks/packages/util/rdb"
+	"time"
+)
+
+const (
+	Redis  = "redis"
+	Memory = "memory"
+)
+
+// tileDuration expires tiles of old dataset revisions, which are never read again
+const tileDuration = 24 * time.Hour
+
+/*
+NewFacilitySvc wires facility service with the storage selected in config,
+"redis" (the default) or "memory" which needs no external process.
+*/
+func NewFacilitySvc(storage string, redisConfig rdb.Config) (*services.FacilitySvc, error) {
+	switch storage {
+	case "", Redis:
+		return newRedisFacilitySvc(redisConfig), nil
+	case Memory:
+		return newMemoryFacilitySvc(), nil
+	default:
+		return nil, fmt.Errorf("unknown storage %q, should be %q or %q", storage, Redis, Memory)
+	}
+}
+
+func newRedisFacilitySvc(redisConfig rdb.Config) *services.FacilitySvc {
+	facilityStore := rdb.NewEntityStore[string, models.Facility]("facility", 0, redisConfig).
+		WithGetKey(models.GetFacilityKey)
+	itemFacilityStore := rdb.NewSliceStore[string, models.Facility]("item", 0, redisConfig, facilityStore).
+		WithGetKey(models.GetFacilityKey).WithGetScore(models.GetFacilityScore)
+	geoFacilityStore := rdb.NewGeoStore[string, models.Facility]("geo", 0, redisConfig, facilityStore).
+		WithGetKey(models.GetFacilityKey).WithGetLocation(models.GetFacilityLocation).
+		WithSliceStore(itemFacilityStore)
+	datasetStore := rdb.NewEntityStore[string, services.DatasetState]("dataset", 0, redisConfig).
+		WithGetKey(services.GetDatasetStateKey)
+	tileStore := rdb.NewEntityStore[string, services.Tile]("tile", tileDuration, redisConfig).
+		WithGetKey(services.GetTileKey)
+	return services.NewFacilitySvc(facilityStore, itemFacilityStore, geoFacilityStore, datasetStore).
+		WithTileStore(tileStore)
+}
+
+func newMemoryFacilitySvc() *services.FacilitySvc {
+	facilityStore := memdb.NewEntityStore[string, models.Facility]().
+		WithGetKey(models.GetFacilityKey)
+	itemFacilityStore := memdb.NewSliceStore[string, models.Facility](facilityStore).
+		WithGetKey(models.GetFacilityKey).WithGetScore(models.GetFacilityScore)
+	geoFacilityStore := memdb.NewGeoStore[string, models.Facility](facilityStore).
+		WithGetKey(models.GetFacilityKey).WithGetLocation(models.GetFacilityLocation).
+		WithSliceStore(itemFacilityStore)
+	datasetStore := memdb.NewEntityStore[string, services.DatasetState]().
+		WithGetKey(services.GetDatasetStateKey)
+	// tiles are encoded in process anyway, a map of tiles of every revision would only grow
+	return services.NewFacilitySvc(facilityStore, itemFacilityStore, geoFacilityStore, datasetStore)
+}
@@ -0,0 +1,110 @@
+package errs
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Kind tells what went wrong regardless of where, the web app maps it to a status code
+type Kind int
+
+const (
+	KindInternal Kind = iota
+	KindInvalid
+	KindNotFound
+	KindConflict
+	KindUnavailable
+	KindUnauthorized
+)
+
+var kindNames = []string{"internal", "invalid", "not found", "conflict", "unavailable", "unauthorized"}
+
+func (k Kind) String() string {
+	if k < 0 || int(k) >= len(kindNames) {
+		return fmt.Sprintf("kind(%d)", int(k))
+	}
+	return kindNames[k]
+}
+
+// Error is an error of a kind, it keeps its kind through Err, Errf and fmt.Errorf wrapping with %w
+type Error struct {
+	Kind Kind
+	// Fields are the invalid fields of a request, if known
+	Fields []FieldError
+	err    error
+}
+
+// FieldError tells why a field of a request is invalid
+type FieldError struct {
+	Field  string `json:"field"`
+	Reason string `json:"reason"`
+}
+
+func (e *Error) Error() string {
+	return e.err.Error()
+}
+
+func (e *Error) Unwrap() error {
+	return e.err
+}
+
+func newError(kind Kind, format string, args ...any) error {
+	return &Error{Kind: kind, err: fmt.Errorf(format, args...)}
+}
+
+// Invalid is for malformed input
+func Invalid(format string, args ...any) error {
+	return newError(KindInvalid, format, args...)
+}
+
+// InvalidFields is Invalid listing every invalid field
+func InvalidFields(fields ...FieldError) error {
+	reasons := make([]string, len(fields))
+	for i, field := range fields {
+		reasons[i] = field.Field + " " + field.Reason
+	}
+	return &Error{
+		Kind:   KindInvalid,
+		Fields: fields,
+		err:    fmt.Errorf("invalid argument: %s", strings.Join(reasons, ", ")),
+	}
+}
+
+// NotFound is for a requested entity that doesn't exist
+func NotFound(format string, args ...any) error {
+	return newError(KindNotFound, format, args...)
+}
+
+// Conflict is for a write clashing with the current state, e.g. creating an existing entity
+func Conflict(format string, args ...any) error {
+	return newError(KindConflict, format, args...)
+}
+
+// Unavailable is for a dependency that failed, retrying later may succeed
+func Unavailable(format string, args ...any) error {
+	return newError(KindUnavailable, format, args...)
+}
+
+// Unauthorized is for a request without valid credentials
+func Unauthorized(format string, args ...any) error {
+	return newError(KindUnauthorized, format, args...)
+}
+
+// KindOf returns the kind of the outermost Error in the chain of err, KindInternal if there is none
+func KindOf(err error) Kind {
+	var e *Error
+	if errors.As(err, &e) {
+		return e.Kind
+	}
+	return KindInternal
+}
+
+// FieldsOf returns the invalid fields of the outermost Error in the chain of err
+func FieldsOf(err error) []FieldError {
+	var e *Error
+	if errors.As(err, &e) {
+		return e.Fields
+	}
+	return nil
+}
@@ -0,0 +1,34 @@
+package errs
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestKindOf(t *testing.T) {
+	notFound := NotFound("not found")
+	cause := errors.New("connection refused")
+	cases := []struct {
+		err  error
+		kind Kind
+	}{
+		{notFound, KindNotFound},
+		{Err(fmt.Errorf("%w: facility 1", notFound)), KindNotFound},
+		{Errf("failed to get, %w", Invalid("bad id")), KindInvalid},
+		{Unavailable("redis: %w", cause), KindUnavailable},
+		{cause, KindInternal},
+		{nil, KindInternal},
+	}
+	for _, c := range cases {
+		if kind := KindOf(c.err); kind != c.kind {
+			t.Fatalf("expect %v of %v, got %v", c.kind, c.err, kind)
+		}
+	}
+	if err := Err(fmt.Errorf("%w: facility 1", notFound)); !errors.Is(err, notFound) {
+		t.Fatalf("expect %v to wrap the sentinel", err)
+	}
+	if err := Unavailable("redis: %w", cause); !errors.Is(err, cause) {
+		t.Fatalf("expect %v to wrap its cause", err)
+	}
+}
@@ -0,0 +1,15 @@
+// Package geo has the distance both store backends measure with, so memory and redis agree on it
+package geo
+
+import "math"
+
+// EarthRadius is in meters, the value redis uses for GEO commands
+const EarthRadius = 6372797.560856
+
+// Distance is the haversine great circle distance in meters, same as GEODIST
+func Distance(lat1, lon1, lat2, lon2 float64) float64 {
+	lat1r, lat2r := lat1*math.Pi/180, lat2*math.Pi/180
+	u := math.Sin((lat2r - lat1r) / 2)
+	v := math.Sin((lon2 - lon1) * math.Pi / 180 / 2)
+	return 2 * EarthRadius * math.Asin(math.Sqrt(u*u+math.Cos(lat1r)*math.Cos(lat2r)*v*v))
+}
@@ -0,0 +1,14 @@
+package geo
+
+import "testing"
+
+func TestDistance(t *testing.T) {
+	// one degree along a meridian is EarthRadius * pi / 180
+	d := Distance(37, -122, 38, -122)
+	if d < 111226 || d > 111227 {
+		t.Fatalf("unexpected distance %v", d)
+	}
+	if d = Distance(37.7955, -122.3937, 37.7955, -122.3937); d != 0 {
+		t.Fatalf("expect 0 to itself, got %v", d)
+	}
+}
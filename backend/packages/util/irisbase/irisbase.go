@@ -3,13 +3,17 @@ package irisbase
 import (
 	"errors"
 	"fmt"
+	"food-trucks/packages/util/errs"
 	"github.com/google/uuid"
+	"github.com/iris-contrib/schema"
 	"github.com/kataras/iris/v12"
 	"github.com/kataras/iris/v12/middleware/cors"
 	irisLogger "github.com/kataras/iris/v12/middleware/logger"
 	"github.com/kataras/iris/v12/middleware/recover"
 	"github.com/kataras/iris/v12/mvc"
 	"github.com/samber/lo"
+	"reflect"
+	"sort"
 )
 
 type AppConfig struct {
@@ -26,40 +30,91 @@ type App struct {
 }
 
 type AppBuilder interface {
-	BadRequest() []error
 	Services() []any
 	Controller() map[string]any
 }
 
-func createErrorHandler(isDebug bool, badRequests []error) errHandler {
+// statusCodes maps error kinds to http status codes, other kinds are 500
+var statusCodes = map[errs.Kind]int{
+	errs.KindInvalid:      iris.StatusBadRequest,
+	errs.KindUnauthorized: iris.StatusUnauthorized,
+	errs.KindNotFound:     iris.StatusNotFound,
+	errs.KindConflict:     iris.StatusConflict,
+	errs.KindUnavailable:  iris.StatusServiceUnavailable,
+}
+
+/*
+createErrorHandler responds with an RFC 7807 problem, the detail is only shown in debug mode,
+invalid fields of a request are always listed in "errors".
+*/
+func createErrorHandler(isDebug bool) errHandler {
 	return func(ctx iris.Context, err error) {
 		id := uuid.New().ID()
-		code := 500
-		for _, err2 := range badRequests {
-			if errors.As(err, &err2) {
-				code = 400
-				break
-			}
+		err = bindingError(err)
+		code, ok := statusCodes[errs.KindOf(err)]
+		if !ok {
+			code = iris.StatusInternalServerError
 		}
-
+		problem := iris.NewProblem().Key("id", id)
 		if isDebug {
-			ctx.StopWithError(code, fmt.Errorf("%w, id=%v", err, id))
-		} else {
-			if code == 400 {
-				ctx.StopWithError(code, fmt.Errorf("bad request, id=%v", id))
-			} else {
-				ctx.StopWithError(code, fmt.Errorf("internal error, id=%v", id))
-			}
+			problem.Detail(err.Error())
+		}
+		if fields := errs.FieldsOf(err); len(fields) > 0 {
+			problem.Key("errors", fields)
 		}
+		ctx.StopWithProblem(code, problem)
 		fmt.Printf("API-ERR: %v, ErrID=%v\n", err, id)
 	}
 }
 
+// bindingError lists query values that can't be converted to their fields as invalid fields, other errors are kept
+func bindingError(err error) error {
+	var multi schema.MultiError
+	var conversion schema.ConversionError
+	switch {
+	case errors.As(err, &multi):
+	case errors.As(err, &conversion):
+		multi = schema.MultiError{conversion.Key: conversion}
+	default:
+		return err
+	}
+	keys := lo.Keys(multi)
+	sort.Strings(keys)
+	var fields []errs.FieldError
+	for _, key := range keys {
+		if errors.As(multi[key], &conversion) {
+			fields = append(fields, errs.FieldError{Field: conversion.Key, Reason: conversionReason(conversion.Type)})
+		}
+	}
+	if len(fields) == 0 {
+		return err
+	}
+	return errs.InvalidFields(fields...)
+}
+
+func conversionReason(t reflect.Type) string {
+	if t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	switch {
+	case t == nil:
+		return "is malformed"
+	case t.Kind() == reflect.Bool:
+		return "should be true or false"
+	case t.Kind() >= reflect.Int && t.Kind() <= reflect.Uint64:
+		return "should be an integer"
+	case t.Kind() == reflect.Float32 || t.Kind() == reflect.Float64:
+		return "should be a number"
+	default:
+		return "is malformed"
+	}
+}
+
 func NewIrisApp(config AppConfig, builder AppBuilder) *App {
 	i := &App{
 		Config: config,
 	}
-	i.ErrHandler = createErrorHandler(config.Debug, builder.BadRequest())
+	i.ErrHandler = createErrorHandler(config.Debug)
 	app := iris.New()
 	app.Use(recover.New())
 	app.Use(irisLogger.New())
@@ -74,6 +129,7 @@ func NewIrisApp(config AppConfig, builder AppBuilder) *App {
 			ReferrerPolicy(cors.NoReferrerWhenDowngrade).
 			AllowOrigin("*").
 			AllowHeaders("content-type, authorization").
+			ExposeHeaders("X-Next-Cursor", "Link").
 			Handler())
 	}
 	app.RegisterDependency(lo.ToAnySlice(builder.Services())...)
@@ -0,0 +1,100 @@
+package irisbase
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"food-trucks/packages/util/errs"
+	"github.com/iris-contrib/schema"
+	"github.com/kataras/iris/v12"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func handleError(isDebug bool, err error) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	ctx := iris.New().ContextPool.Acquire(w, httptest.NewRequest("GET", "/api/facilities", nil))
+	createErrorHandler(isDebug)(ctx, err)
+	return w
+}
+
+func TestCreateErrorHandler_StatusCode(t *testing.T) {
+	cases := []struct {
+		err  error
+		code int
+	}{
+		{errs.Invalid("bad"), iris.StatusBadRequest},
+		{errs.Unauthorized("no token"), iris.StatusUnauthorized},
+		{errs.NotFound("no facility"), iris.StatusNotFound},
+		{errs.Conflict("exists"), iris.StatusConflict},
+		{errs.Unavailable("redis unavailable"), iris.StatusServiceUnavailable},
+		{fmt.Errorf("get: %w", errs.Unavailable("redis unavailable")), iris.StatusServiceUnavailable},
+		{errors.New("boom"), iris.StatusInternalServerError},
+	}
+	for _, c := range cases {
+		w := handleError(false, c.err)
+		if w.Code != c.code {
+			t.Errorf("%v: expect %d, got %d", c.err, c.code, w.Code)
+		}
+		if contentType := w.Header().Get("Content-Type"); contentType != "application/problem+json; charset=utf-8" {
+			t.Errorf("%v: unexpected content type %q", c.err, contentType)
+		}
+	}
+}
+
+func TestCreateErrorHandler_Problem(t *testing.T) {
+	var problem struct {
+		ID     uint32            `json:"id"`
+		Status int               `json:"status"`
+		Detail string            `json:"detail"`
+		Errors []errs.FieldError `json:"errors"`
+	}
+	fields := []errs.FieldError{{Field: "lat", Reason: "should be at most 90"}}
+	w := handleError(false, errs.InvalidFields(fields...))
+	if err := json.Unmarshal(w.Body.Bytes(), &problem); err != nil {
+		t.Fatal(err)
+	}
+	if problem.ID == 0 || problem.Status != iris.StatusBadRequest || problem.Detail != "" ||
+		!reflect.DeepEqual(problem.Errors, fields) {
+		t.Fatalf("unexpected problem %s", w.Body.String())
+	}
+
+	problem.Errors = nil
+	w = handleError(true, errors.New("boom"))
+	if err := json.Unmarshal(w.Body.Bytes(), &problem); err != nil {
+		t.Fatal(err)
+	}
+	if problem.Detail != "boom" || problem.Errors != nil {
+		t.Fatalf("expect the detail in debug mode only, got %s", w.Body.String())
+	}
+}
+
+func TestBindingError(t *testing.T) {
+	var qry struct {
+		Lat   *float64 `url:"lat"`
+		Count int      `url:"count"`
+		Open  bool     `url:"open"`
+		Name  string   `url:"name"`
+	}
+	err := schema.DecodeQuery(map[string][]string{"lat": {"abc"}, "count": {"1.5"}, "open": {"maybe"}, "name": {"x"}}, &qry)
+	if err == nil {
+		t.Fatal("expect conversion errors")
+	}
+	err = bindingError(err)
+	expected := []errs.FieldError{
+		{Field: "count", Reason: "should be an integer"},
+		{Field: "lat", Reason: "should be a number"},
+		{Field: "open", Reason: "should be true or false"},
+	}
+	if errs.KindOf(err) != errs.KindInvalid || !reflect.DeepEqual(errs.FieldsOf(err), expected) {
+		t.Fatalf("unexpected %v %+v", err, errs.FieldsOf(err))
+	}
+
+	if other := errors.New("boom"); bindingError(other) != other {
+		t.Fatal("expect other errors kept")
+	}
+	if w := handleError(false, schema.ConversionError{Key: "lat", Index: -1}); w.Code != iris.StatusBadRequest {
+		t.Fatalf("expect 400, got %d", w.Code)
+	}
+}
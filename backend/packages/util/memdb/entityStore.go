@@ -0,0 +1,74 @@
+package memdb
+
+import (
+	"context"
+	"errors"
+	"golang.org/x/exp/constraints"
+	"sync"
+)
+
+/*
+EntityStore keeps entities in a process local map, it has the same shape as rdb.EntityStore,
+so services can swap redis for memory without code change.
+*/
+type EntityStore[K constraints.Ordered, V any] struct {
+	mu     sync.RWMutex
+	items  map[K]V
+	getKey func(V) K
+}
+
+func NewEntityStore[K constraints.Ordered, V any]() *EntityStore[K, V] {
+	return &EntityStore[K, V]{
+		items: make(map[K]V),
+	}
+}
+
+func (c *EntityStore[K, V]) WithGetKey(f func(V) K) *EntityStore[K, V] {
+	c.getKey = f
+	return c
+}
+
+func (c *EntityStore[K, V]) Set(ctx context.Context, vals []V) error {
+	if c.getKey == nil {
+		return errors.New("getKey not set")
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, val := range vals {
+		c.items[c.getKey(val)] = val
+	}
+	return nil
+}
+
+func (c *EntityStore[K, V]) Del(ctx context.Context, ids ...K) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, id := range ids {
+		delete(c.items, id)
+	}
+	return nil
+}
+
+// Keys returns the key of every stored entity, in no order
+func (c *EntityStore[K, V]) Keys(ctx context.Context) ([]K, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	keys := make([]K, 0, len(c.items))
+	for key := range c.items {
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+// Get returns entities in the order of keys, missing keys are skipped
+func (c *EntityStore[K, V]) Get(ctx context.Context, keys []K) ([]V, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	ret := make([]V, 0, len(keys))
+	for _, key := range keys {
+		if val, ok := c.items[key]; ok {
+			ret = append(ret, val)
+		}
+	}
+	return ret, nil
+}
@@ -0,0 +1,173 @@
+package memdb
+
+import (
+	"food-trucks/packages/util/geo"
+	"golang.org/x/exp/constraints"
+	"math"
+	"sort"
+)
+
+// defaultCellSize is in degrees, about 1.1km of latitude
+const defaultCellSize = 0.01
+
+type point struct {
+	Lat float64
+	Lon float64
+}
+
+type cell struct {
+	Lat int
+	Lon int
+}
+
+type geoHit[K constraints.Ordered] struct {
+	Key  K
+	Dist float64
+	point
+}
+
+/*
+geoIndex is a uniform grid spatial index, points are bucketed into cells of cellSize degrees,
+a query only visits cells overlapping the query's bounding box instead of scanning every point.
+*/
+type geoIndex[K constraints.Ordered] struct {
+	cellSize float64
+	cells    map[cell]map[K]point
+	points   map[K]point
+}
+
+func newGeoIndex[K constraints.Ordered](cellSize float64) *geoIndex[K] {
+	return &geoIndex[K]{
+		cellSize: cellSize,
+		cells:    make(map[cell]map[K]point),
+		points:   make(map[K]point),
+	}
+}
+
+func (g *geoIndex[K]) cellOf(lat, lon float64) cell {
+	return cell{
+		Lat: int(math.Floor(lat / g.cellSize)),
+		Lon: int(math.Floor(lon / g.cellSize)),
+	}
+}
+
+// add inserts or moves a key, like GEOADD on an existing member
+func (g *geoIndex[K]) add(key K, lat, lon float64) {
+	g.remove(key)
+	p := point{Lat: lat, Lon: lon}
+	c := g.cellOf(lat, lon)
+	members, ok := g.cells[c]
+	if !ok {
+		members = make(map[K]point)
+		g.cells[c] = members
+	}
+	members[key] = p
+	g.points[key] = p
+}
+
+func (g *geoIndex[K]) remove(key K) {
+	p, ok := g.points[key]
+	if !ok {
+		return
+	}
+	c := g.cellOf(p.Lat, p.Lon)
+	delete(g.cells[c], key)
+	if len(g.cells[c]) == 0 {
+		delete(g.cells, c)
+	}
+	delete(g.points, key)
+}
+
+// inBox calls fn for every point inside the box (inclusive)
+func (g *geoIndex[K]) inBox(minLat, minLon, maxLat, maxLon float64, fn func(K, point)) {
+	from, to := g.cellOf(minLat, minLon), g.cellOf(maxLat, maxLon)
+	visit := func(members map[K]point) {
+		for k, p := range members {
+			if p.Lat >= minLat && p.Lat <= maxLat && p.Lon >= minLon && p.Lon <= maxLon {
+				fn(k, p)
+			}
+		}
+	}
+	// a huge box covers more cells than are occupied, walk the occupied ones instead
+	boxCells := float64(to.Lat-from.Lat+1) * float64(to.Lon-from.Lon+1)
+	if boxCells > float64(len(g.cells)) {
+		for c, members := range g.cells {
+			if c.Lat >= from.Lat && c.Lat <= to.Lat && c.Lon >= from.Lon && c.Lon <= to.Lon {
+				visit(members)
+			}
+		}
+		return
+	}
+	for lat := from.Lat; lat <= to.Lat; lat++ {
+		for lon := from.Lon; lon <= to.Lon; lon++ {
+			visit(g.cells[cell{Lat: lat, Lon: lon}])
+		}
+	}
+}
+
+// radius returns keys within meters of the center, nearest first
+func (g *geoIndex[K]) radius(lat, lon, meters float64) []geoHit[K] {
+	dLat := meters / geo.EarthRadius * 180 / math.Pi
+	minLat, maxLat := math.Max(lat-dLat, -90), math.Min(lat+dLat, 90)
+	minLon, maxLon := -180.0, 180.0
+	if cos := math.Cos(lat * math.Pi / 180); maxLat < 90 && minLat > -90 && cos > 0 {
+		dLon := dLat / cos
+		if dLon < 180 {
+			minLon, maxLon = lon-dLon, lon+dLon
+		}
+	}
+
+	var hits []geoHit[K]
+	collect := func(k K, p point) {
+		if d := geo.Distance(lat, lon, p.Lat, p.Lon); d <= meters {
+			hits = append(hits, geoHit[K]{Key: k, Dist: d, point: p})
+		}
+	}
+	// the box may wrap around the anti meridian
+	switch {
+	case minLon < -180:
+		g.inBox(minLat, minLon+360, maxLat, 180, collect)
+		g.inBox(minLat, -180, maxLat, maxLon, collect)
+	case maxLon > 180:
+		g.inBox(minLat, minLon, maxLat, 180, collect)
+		g.inBox(minLat, -180, maxLat, maxLon-360, collect)
+	default:
+		g.inBox(minLat, minLon, maxLat, maxLon, collect)
+	}
+	sortHits(hits)
+	return hits
+}
+
+/*
+nearest returns at most count keys within meters of the center, nearest first.
+It searches growing circles, points outside a circle are farther than every point inside it.
+*/
+func (g *geoIndex[K]) nearest(lat, lon, meters float64, count int) []geoHit[K] {
+	if count <= 0 {
+		return g.radius(lat, lon, meters)
+	}
+	r := g.cellSize * math.Pi / 180 * geo.EarthRadius
+	for {
+		if r >= meters {
+			hits := g.radius(lat, lon, meters)
+			return hits[:min(count, len(hits))]
+		}
+		hits := g.radius(lat, lon, r)
+		if len(hits) >= count {
+			return hits[:count]
+		}
+		if len(hits) == len(g.points) {
+			return hits
+		}
+		r *= 4
+	}
+}
+
+func sortHits[K constraints.Ordered](hits []geoHit[K]) {
+	sort.Slice(hits, func(i, j int) bool {
+		if hits[i].Dist != hits[j].Dist {
+			return hits[i].Dist < hits[j].Dist
+		}
+		return hits[i].Key < hits[j].Key
+	})
+}
@@ -0,0 +1,189 @@
+package memdb
+
+import (
+	"context"
+	"fmt"
+	"food-trucks/packages/util/geo"
+	"golang.org/x/exp/constraints"
+	"math"
+	"sync"
+)
+
+/*
+GeoStore indexes entity locations in a grid, the in memory counterpart of rdb.GeoStore.
+Radius is in km, same as rdb.GeoStore.
+*/
+type GeoStore[K constraints.Ordered, Entity any] struct {
+	mu           sync.RWMutex
+	index        *geoIndex[K]
+	entityStore  Cacheable[K, Entity]
+	getMemberKey func(Entity) K
+	getLocation  func(Entity) (float64, float64)
+	sliceStore   *SliceStore[K, Entity]
+}
+
+func NewGeoStore[MemberKey constraints.Ordered, Entity any](
+	entityStore Cacheable[MemberKey, Entity],
+) *GeoStore[MemberKey, Entity] {
+	return &GeoStore[MemberKey, Entity]{
+		index:       newGeoIndex[MemberKey](defaultCellSize),
+		entityStore: entityStore,
+	}
+}
+
+func (s *GeoStore[K, V]) WithGetKey(f func(V) K) *GeoStore[K, V] {
+	s.getMemberKey = f
+	return s
+}
+
+func (s *GeoStore[K, V]) WithGetLocation(f func(V) (lat float64, lon float64)) *GeoStore[K, V] {
+	s.getLocation = f
+	return s
+}
+
+// WithCellSize sets grid cell size in degrees, must be called before adding items
+func (s *GeoStore[K, V]) WithCellSize(degrees float64) *GeoStore[K, V] {
+	s.index = newGeoIndex[K](degrees)
+	return s
+}
+
+// WithSliceStore enables GetNearestInSlice, the slice store should hold the same members
+func (s *GeoStore[K, V]) WithSliceStore(sliceStore *SliceStore[K, V]) *GeoStore[K, V] {
+	s.sliceStore = sliceStore
+	return s
+}
+
+func (s *GeoStore[K, V]) Add(ctx context.Context, item V) error {
+	lat, lon := s.getLocation(item)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.index.add(s.getMemberKey(item), lat, lon)
+	return nil
+}
+
+func (s *GeoStore[K, V]) AddAll(ctx context.Context, items []V) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, item := range items {
+		lat, lon := s.getLocation(item)
+		s.index.add(s.getMemberKey(item), lat, lon)
+	}
+	return nil
+}
+
+func (s *GeoStore[K, V]) Del(ctx context.Context, ids ...K) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, id := range ids {
+		s.index.remove(id)
+	}
+	return nil
+}
+
+// Keys returns the key of every member, in no order
+func (s *GeoStore[K, V]) Keys(ctx context.Context) ([]K, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	keys := make([]K, 0, len(s.index.points))
+	for key := range s.index.points {
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+func (s *GeoStore[K, V]) Get(ctx context.Context, lat float64, lon float64, radius float64) ([]V, error) {
+	s.mu.RLock()
+	hits := s.index.radius(lat, lon, radius*1000)
+	s.mu.RUnlock()
+	items, _, err := s.getEntities(ctx, hits)
+	return items, err
+}
+
+/*
+GetNearest returns at most count entities nearest to the point first, with distances in meters.
+radius is in km, radius <= 0 means no limit, count <= 0 means no limit.
+*/
+func (s *GeoStore[K, V]) GetNearest(ctx context.Context, lat float64, lon float64, radius float64, count int) ([]V, []float64, error) {
+	meters := math.Inf(1)
+	if radius > 0 {
+		meters = radius * 1000
+	}
+	s.mu.RLock()
+	hits := s.index.nearest(lat, lon, meters, count)
+	s.mu.RUnlock()
+	return s.getEntities(ctx, hits)
+}
+
+/*
+GetNearestInSlice is GetNearest limited to members of a slice of the slice store.
+A slice is usually much smaller than the grid, so distances are computed for its members only.
+*/
+func (s *GeoStore[K, V]) GetNearestInSlice(ctx context.Context, sliceID any, lat float64, lon float64, radius float64, count int) ([]V, []float64, error) {
+	if s.sliceStore == nil {
+		return nil, nil, fmt.Errorf("geo store has no slice store")
+	}
+	meters := math.Inf(1)
+	if radius > 0 {
+		meters = radius * 1000
+	}
+	members := s.sliceStore.memberKeys(sliceID)
+	var hits []geoHit[K]
+	s.mu.RLock()
+	for _, k := range members {
+		p, ok := s.index.points[k]
+		if !ok {
+			continue
+		}
+		if d := geo.Distance(lat, lon, p.Lat, p.Lon); d <= meters {
+			hits = append(hits, geoHit[K]{Key: k, Dist: d, point: p})
+		}
+	}
+	s.mu.RUnlock()
+	sortHits(hits)
+	if count > 0 && len(hits) > count {
+		hits = hits[:count]
+	}
+	return s.getEntities(ctx, hits)
+}
+
+// GetInBox returns at most count entities inside the lat/lon box, nearest to the box center first, count <= 0 means no limit
+func (s *GeoStore[K, V]) GetInBox(ctx context.Context, minLat, minLon, maxLat, maxLon float64, count int) ([]V, error) {
+	centerLat, centerLon := (minLat+maxLat)/2, (minLon+maxLon)/2
+	var hits []geoHit[K]
+	s.mu.RLock()
+	s.index.inBox(minLat, minLon, maxLat, maxLon, func(k K, p point) {
+		hits = append(hits, geoHit[K]{Key: k, Dist: geo.Distance(centerLat, centerLon, p.Lat, p.Lon), point: p})
+	})
+	s.mu.RUnlock()
+	sortHits(hits)
+	if count > 0 && len(hits) > count {
+		hits = hits[:count]
+	}
+	items, _, err := s.getEntities(ctx, hits)
+	return items, err
+}
+
+// getEntities keeps the order of hits, hits whose entity is missing are dropped
+func (s *GeoStore[K, V]) getEntities(ctx context.Context, hits []geoHit[K]) ([]V, []float64, error) {
+	keys := make([]K, len(hits))
+	for i, hit := range hits {
+		keys[i] = hit.Key
+	}
+	items, err := s.entityStore.Get(ctx, keys)
+	if err != nil {
+		return nil, nil, err
+	}
+	byKey := make(map[K]V, len(items))
+	for _, item := range items {
+		byKey[s.getMemberKey(item)] = item
+	}
+	ret := make([]V, 0, len(hits))
+	dists := make([]float64, 0, len(hits))
+	for _, hit := range hits {
+		if item, ok := byKey[hit.Key]; ok {
+			ret = append(ret, item)
+			dists = append(dists, hit.Dist)
+		}
+	}
+	return ret, dists, nil
+}
@@ -0,0 +1,180 @@
+package memdb
+
+import (
+	"context"
+	"fmt"
+	"testing"
+)
+
+type GeoPost struct {
+	ID  string
+	Lat float64
+	Lon float64
+}
+
+func GeoPostID(p GeoPost) string {
+	return p.ID
+}
+
+func GeoPostLocation(p GeoPost) (float64, float64) {
+	return p.Lat, p.Lon
+}
+
+func newGeoPostStore() *GeoStore[string, GeoPost] {
+	entityStore := NewEntityStore[string, GeoPost]().WithGetKey(GeoPostID)
+	return NewGeoStore[string, GeoPost](entityStore).WithGetKey(GeoPostID).WithGetLocation(GeoPostLocation)
+}
+
+func addGeoPosts(t *testing.T, store *GeoStore[string, GeoPost], posts ...GeoPost) {
+	ctx := context.Background()
+	if err := store.entityStore.Set(ctx, posts); err != nil {
+		t.Fatal(err)
+	}
+	for _, post := range posts {
+		if err := store.Add(ctx, post); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestGeoStore_Get(t *testing.T) {
+	store := newGeoPostStore()
+	addGeoPosts(t, store,
+		GeoPost{ID: "ferry", Lat: 37.7955, Lon: -122.3937},
+		GeoPost{ID: "pier39", Lat: 37.8087, Lon: -122.4098},
+		GeoPost{ID: "sfo", Lat: 37.6213, Lon: -122.3790},
+	)
+	items, err := store.Get(context.Background(), 37.7955, -122.3937, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := fmt.Sprint(items); len(items) != 2 || items[0].ID != "ferry" || items[1].ID != "pier39" {
+		t.Fatalf("expect ferry and pier39 nearest first, got %v", got)
+	}
+}
+
+func TestGeoStore_AddMoves(t *testing.T) {
+	store := newGeoPostStore()
+	addGeoPosts(t, store, GeoPost{ID: "truck", Lat: 37.7955, Lon: -122.3937})
+	addGeoPosts(t, store, GeoPost{ID: "truck", Lat: 37.6213, Lon: -122.3790})
+	items, err := store.Get(context.Background(), 37.7955, -122.3937, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 0 {
+		t.Fatalf("expect moved truck to leave old location, got %v", items)
+	}
+}
+
+func TestGeoStore_AntiMeridian(t *testing.T) {
+	store := newGeoPostStore()
+	addGeoPosts(t, store, GeoPost{ID: "east", Lat: 0, Lon: 179.999}, GeoPost{ID: "west", Lat: 0, Lon: -179.999})
+	items, err := store.Get(context.Background(), 0, 179.999, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 2 {
+		t.Fatalf("expect both sides of anti meridian, got %v", items)
+	}
+}
+
+func TestGeoStore_GetNearest(t *testing.T) {
+	store := newGeoPostStore()
+	addGeoPosts(t, store,
+		GeoPost{ID: "ferry", Lat: 37.7955, Lon: -122.3937},
+		GeoPost{ID: "pier39", Lat: 37.8087, Lon: -122.4098},
+		GeoPost{ID: "sfo", Lat: 37.6213, Lon: -122.3790},
+		GeoPost{ID: "nyc", Lat: 40.7128, Lon: -74.0060},
+	)
+	ctx := context.Background()
+	items, dists, err := store.GetNearest(ctx, 37.7955, -122.3937, 0, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 3 || items[0].ID != "ferry" || items[1].ID != "pier39" || items[2].ID != "sfo" {
+		t.Fatalf("expect 3 nearest in order, got %v", items)
+	}
+	if dists[0] != 0 || dists[1] < dists[0] || dists[2] < 19000 || dists[2] > 20000 {
+		t.Fatalf("unexpected distances %v", dists)
+	}
+
+	items, _, err = store.GetNearest(ctx, 37.7955, -122.3937, 0, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 4 || items[3].ID != "nyc" {
+		t.Fatalf("expect all 4 with nyc last, got %v", items)
+	}
+
+	items, _, err = store.GetNearest(ctx, 37.7955, -122.3937, 5, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 2 {
+		t.Fatalf("expect radius to limit to 2, got %v", items)
+	}
+}
+
+func TestGeoStore_GetNearestInSlice(t *testing.T) {
+	entityStore := NewEntityStore[string, GeoPost]().WithGetKey(GeoPostID)
+	sliceStore := NewSliceStore[string, GeoPost](entityStore).WithGetKey(GeoPostID).
+		WithGetScore(func(GeoPost) float64 { return 0 })
+	store := NewGeoStore[string, GeoPost](entityStore).WithGetKey(GeoPostID).WithGetLocation(GeoPostLocation).
+		WithSliceStore(sliceStore)
+	addGeoPosts(t, store,
+		GeoPost{ID: "ferry", Lat: 37.7955, Lon: -122.3937},
+		GeoPost{ID: "pier39", Lat: 37.8087, Lon: -122.4098},
+		GeoPost{ID: "sfo", Lat: 37.6213, Lon: -122.3790},
+	)
+	ctx := context.Background()
+	if err := sliceStore.AddMem(ctx, "tacos", []GeoPost{
+		{ID: "sfo", Lat: 37.6213, Lon: -122.3790},
+		{ID: "pier39", Lat: 37.8087, Lon: -122.4098},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	items, dists, err := store.GetNearestInSlice(ctx, "tacos", 37.7955, -122.3937, 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 2 || items[0].ID != "pier39" || items[1].ID != "sfo" || dists[0] > dists[1] {
+		t.Fatalf("expect slice members nearest first, got %v %v", items, dists)
+	}
+
+	items, _, err = store.GetNearestInSlice(ctx, "tacos", 37.7955, -122.3937, 5, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 1 || items[0].ID != "pier39" {
+		t.Fatalf("expect radius to limit to pier39, got %v", items)
+	}
+
+	items, _, err = store.GetNearestInSlice(ctx, "burgers", 37.7955, -122.3937, 0, 0)
+	if err != nil || len(items) != 0 {
+		t.Fatalf("expect nothing for unknown slice, got %v %v", items, err)
+	}
+}
+
+func TestGeoStore_GetInBox(t *testing.T) {
+	store := newGeoPostStore()
+	addGeoPosts(t, store,
+		GeoPost{ID: "ferry", Lat: 37.7955, Lon: -122.3937},
+		GeoPost{ID: "pier39", Lat: 37.8087, Lon: -122.4098},
+		GeoPost{ID: "sfo", Lat: 37.6213, Lon: -122.3790},
+	)
+	items, err := store.GetInBox(context.Background(), 37.79, -122.41, 37.81, -122.39, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 2 {
+		t.Fatalf("expect ferry and pier39, got %v", items)
+	}
+	// the box center is nearer to the ferry
+	items, err = store.GetInBox(context.Background(), 37.79, -122.41, 37.81, -122.39, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 1 || items[0].ID != "ferry" {
+		t.Fatalf("expect ferry only, got %v", items)
+	}
+}
@@ -0,0 +1,157 @@
+package memdb
+
+import (
+	"context"
+	"fmt"
+	"food-trucks/packages/util/errs"
+	"golang.org/x/exp/constraints"
+	"sort"
+	"sync"
+)
+
+/*
+SliceStore is an inverted index from slice id to scored member keys,
+the in memory counterpart of rdb.SliceStore (a redis zset per slice).
+*/
+type SliceStore[K constraints.Ordered, Entity any] struct {
+	mu           sync.RWMutex
+	slices       map[string]map[K]float64
+	entityStore  Cacheable[K, Entity]
+	getMemberKey func(Entity) K
+	getScore     func(Entity) float64
+}
+
+func NewSliceStore[MemberKey constraints.Ordered, Entity any](
+	entityStore Cacheable[MemberKey, Entity],
+) *SliceStore[MemberKey, Entity] {
+	return &SliceStore[MemberKey, Entity]{
+		slices:      make(map[string]map[MemberKey]float64),
+		entityStore: entityStore,
+	}
+}
+
+func (s *SliceStore[K, V]) WithGetKey(f func(V) K) *SliceStore[K, V] {
+	s.getMemberKey = f
+	return s
+}
+
+func (s *SliceStore[K, V]) WithGetScore(f func(V) float64) *SliceStore[K, V] {
+	s.getScore = f
+	return s
+}
+
+func (s *SliceStore[K, V]) DelSlice(ctx context.Context, sliceID any) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.slices, s.sliceKey(sliceID))
+	return nil
+}
+
+// SliceIDs returns the id of every stored slice, in no order
+func (s *SliceStore[K, V]) SliceIDs(ctx context.Context) ([]string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	ids := make([]string, 0, len(s.slices))
+	for id := range s.slices {
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+func (s *SliceStore[K, V]) DelMember(ctx context.Context, sliceID any, memberKeys []K) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key := s.sliceKey(sliceID)
+	members := s.slices[key]
+	for _, memberKey := range memberKeys {
+		delete(members, memberKey)
+	}
+	if len(members) == 0 {
+		delete(s.slices, key)
+	}
+	return nil
+}
+
+func (s *SliceStore[K, Entity]) AddMem(ctx context.Context, sliceID any, items []Entity) error {
+	if err := s.entityStore.Set(ctx, items); err != nil {
+		return errs.Err(err)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key := s.sliceKey(sliceID)
+	members, ok := s.slices[key]
+	if !ok {
+		members = make(map[K]float64)
+		s.slices[key] = members
+	}
+	for _, item := range items {
+		members[s.getMemberKey(item)] = s.getScore(item)
+	}
+	return nil
+}
+
+/*
+AddMembers adds members to many slices at once, members is keyed by slice id.
+Unlike AddMem it doesn't set entities, they should be set to the entity store separately.
+*/
+func (s *SliceStore[K, Entity]) AddMembers(ctx context.Context, members map[any][]Entity) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for sliceID, items := range members {
+		key := s.sliceKey(sliceID)
+		slice, ok := s.slices[key]
+		if !ok {
+			slice = make(map[K]float64)
+			s.slices[key] = slice
+		}
+		for _, item := range items {
+			slice[s.getMemberKey(item)] = s.getScore(item)
+		}
+	}
+	return nil
+}
+
+// GetAllMemberEntities returns entities ordered by score desc, same as redis ZREVRANGEBYSCORE
+func (s *SliceStore[K, Entity]) GetAllMemberEntities(ctx context.Context, sliceID any) ([]Entity, error) {
+	items, err := s.entityStore.Get(ctx, s.memberKeys(sliceID))
+	if err != nil {
+		return nil, errs.Err(err)
+	}
+	return items, nil
+}
+
+// GetMemberEntities is a page of GetAllMemberEntities, count <= 0 means all members after offset
+func (s *SliceStore[K, Entity]) GetMemberEntities(ctx context.Context, sliceID any, offset int, count int) ([]Entity, error) {
+	keys := s.memberKeys(sliceID)
+	keys = keys[min(max(offset, 0), len(keys)):]
+	if count > 0 && len(keys) > count {
+		keys = keys[:count]
+	}
+	items, err := s.entityStore.Get(ctx, keys)
+	if err != nil {
+		return nil, errs.Err(err)
+	}
+	return items, nil
+}
+
+func (s *SliceStore[K, V]) memberKeys(sliceID any) []K {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	members := s.slices[s.sliceKey(sliceID)]
+	keys := make([]K, 0, len(members))
+	for k := range members {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		si, sj := members[keys[i]], members[keys[j]]
+		if si != sj {
+			return si > sj
+		}
+		return keys[i] > keys[j]
+	})
+	return keys
+}
+
+func (s *SliceStore[K, V]) sliceKey(sliceID any) string {
+	return fmt.Sprintf("%v", sliceID)
+}
@@ -0,0 +1,81 @@
+package memdb
+
+import (
+	"context"
+	"testing"
+)
+
+type SlicePost struct {
+	ID    string
+	Score float64
+}
+
+func SlicePostID(p SlicePost) string {
+	return p.ID
+}
+
+func SlicePostScore(p SlicePost) float64 {
+	return p.Score
+}
+
+func newSlicePostStore() *SliceStore[string, SlicePost] {
+	entityStore := NewEntityStore[string, SlicePost]().WithGetKey(SlicePostID)
+	return NewSliceStore[string, SlicePost](entityStore).WithGetKey(SlicePostID).WithGetScore(SlicePostScore)
+}
+
+func TestSliceStore_GetAllMemberEntities(t *testing.T) {
+	ctx := context.Background()
+	store := newSlicePostStore()
+	if err := store.AddMem(ctx, "tacos", []SlicePost{{ID: "a", Score: 1}, {ID: "b", Score: 3}, {ID: "c", Score: 1}}); err != nil {
+		t.Fatal(err)
+	}
+	items, err := store.GetAllMemberEntities(ctx, "tacos")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 3 || items[0].ID != "b" || items[1].ID != "c" || items[2].ID != "a" {
+		t.Fatalf("expect score desc then member desc, got %v", items)
+	}
+}
+
+func TestSliceStore_GetMemberEntities(t *testing.T) {
+	ctx := context.Background()
+	store := newSlicePostStore()
+	if err := store.AddMem(ctx, "tacos", []SlicePost{{ID: "a", Score: 1}, {ID: "b", Score: 3}, {ID: "c", Score: 1}}); err != nil {
+		t.Fatal(err)
+	}
+	items, err := store.GetMemberEntities(ctx, "tacos", 1, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 1 || items[0].ID != "c" {
+		t.Fatalf("expect the second member, got %v", items)
+	}
+	if items, _ = store.GetMemberEntities(ctx, "tacos", 1, 0); len(items) != 2 {
+		t.Fatalf("expect all after offset, got %v", items)
+	}
+	if items, _ = store.GetMemberEntities(ctx, "tacos", 5, 1); len(items) != 0 {
+		t.Fatalf("expect nothing past the end, got %v", items)
+	}
+}
+
+func TestSliceStore_DelMember(t *testing.T) {
+	ctx := context.Background()
+	store := newSlicePostStore()
+	if err := store.AddMem(ctx, "tacos", []SlicePost{{ID: "a"}, {ID: "b"}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.DelMember(ctx, "tacos", []string{"a"}); err != nil {
+		t.Fatal(err)
+	}
+	items, err := store.GetAllMemberEntities(ctx, "tacos")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 1 || items[0].ID != "b" {
+		t.Fatalf("expect only b left, got %v", items)
+	}
+	if items, _ := store.GetAllMemberEntities(ctx, "burritos"); len(items) != 0 {
+		t.Fatalf("expect empty slice, got %v", items)
+	}
+}
@@ -0,0 +1,187 @@
+/*
+Package mvt encodes point features as Mapbox Vector Tiles (spec 2.1), enough for markers of a map layer.
+Only the protobuf wire format is used, so there is no generated code.
+*/
+package mvt
+
+import (
+	"fmt"
+	"google.golang.org/protobuf/encoding/protowire"
+	"math"
+	"sort"
+)
+
+// DefaultExtent is the number of units along each side of a tile, points are in tile units from its top left
+const DefaultExtent = 4096
+
+const (
+	version   = 2
+	geomPoint = 1
+	cmdMoveTo = 1
+	// tileLayers is the field number of layers in a Tile message
+	tileLayers = 3
+)
+
+// Layer collects point features, keys and values are deduplicated across its features as the spec requires
+type Layer struct {
+	name     string
+	extent   uint32
+	keys     []string
+	keyIdx   map[string]uint32
+	values   [][]byte
+	valueIdx map[string]uint32
+	features [][]byte
+}
+
+func NewLayer(name string, extent uint32) *Layer {
+	return &Layer{
+		name:     name,
+		extent:   extent,
+		keyIdx:   make(map[string]uint32),
+		valueIdx: make(map[string]uint32),
+	}
+}
+
+func (l *Layer) Extent() uint32 {
+	return l.extent
+}
+
+func (l *Layer) Len() int {
+	return len(l.features)
+}
+
+/*
+AddPoint adds a point at x, y tile units, id 0 means the feature has no id.
+Properties are string, bool, int, int64, uint64, float32 or float64, other types are an error.
+*/
+func (l *Layer) AddPoint(id uint64, x, y int, properties map[string]any) error {
+	names := make([]string, 0, len(properties))
+	for name := range properties {
+		names = append(names, name)
+	}
+	// sorted so a tile is encoded the same every time
+	sort.Strings(names)
+	var tags []uint32
+	for _, name := range names {
+		value, err := l.valueIndex(properties[name])
+		if err != nil {
+			return fmt.Errorf("property %s, %w", name, err)
+		}
+		tags = append(tags, l.keyIndex(name), value)
+	}
+
+	var feature []byte
+	if id != 0 {
+		feature = protowire.AppendTag(feature, 1, protowire.VarintType)
+		feature = protowire.AppendVarint(feature, id)
+	}
+	if len(tags) > 0 {
+		feature = appendPacked(feature, 2, tags)
+	}
+	feature = protowire.AppendTag(feature, 3, protowire.VarintType)
+	feature = protowire.AppendVarint(feature, geomPoint)
+	feature = appendPacked(feature, 4, []uint32{
+		cmdMoveTo&0x7 | 1<<3,
+		uint32(protowire.EncodeZigZag(int64(x))),
+		uint32(protowire.EncodeZigZag(int64(y))),
+	})
+	l.features = append(l.features, feature)
+	return nil
+}
+
+func (l *Layer) keyIndex(key string) uint32 {
+	if i, ok := l.keyIdx[key]; ok {
+		return i
+	}
+	i := uint32(len(l.keys))
+	l.keys = append(l.keys, key)
+	l.keyIdx[key] = i
+	return i
+}
+
+func (l *Layer) valueIndex(v any) (uint32, error) {
+	value, err := encodeValue(v)
+	if err != nil {
+		return 0, err
+	}
+	if i, ok := l.valueIdx[string(value)]; ok {
+		return i, nil
+	}
+	i := uint32(len(l.values))
+	l.values = append(l.values, value)
+	l.valueIdx[string(value)] = i
+	return i, nil
+}
+
+// encodeValue is a Value message, whose field number tells the type
+func encodeValue(v any) ([]byte, error) {
+	var b []byte
+	switch v := v.(type) {
+	case string:
+		b = protowire.AppendTag(b, 1, protowire.BytesType)
+		b = protowire.AppendString(b, v)
+	case float32:
+		b = protowire.AppendTag(b, 2, protowire.Fixed32Type)
+		b = protowire.AppendFixed32(b, math.Float32bits(v))
+	case float64:
+		b = protowire.AppendTag(b, 3, protowire.Fixed64Type)
+		b = protowire.AppendFixed64(b, math.Float64bits(v))
+	case int:
+		b = protowire.AppendTag(b, 6, protowire.VarintType)
+		b = protowire.AppendVarint(b, protowire.EncodeZigZag(int64(v)))
+	case int64:
+		b = protowire.AppendTag(b, 6, protowire.VarintType)
+		b = protowire.AppendVarint(b, protowire.EncodeZigZag(v))
+	case uint64:
+		b = protowire.AppendTag(b, 5, protowire.VarintType)
+		b = protowire.AppendVarint(b, v)
+	case bool:
+		b = protowire.AppendTag(b, 7, protowire.VarintType)
+		b = protowire.AppendVarint(b, protowire.EncodeBool(v))
+	default:
+		return nil, fmt.Errorf("unsupported value type %T", v)
+	}
+	return b, nil
+}
+
+func appendPacked(b []byte, num protowire.Number, vs []uint32) []byte {
+	var packed []byte
+	for _, v := range vs {
+		packed = protowire.AppendVarint(packed, uint64(v))
+	}
+	b = protowire.AppendTag(b, num, protowire.BytesType)
+	return protowire.AppendBytes(b, packed)
+}
+
+// Encode is a Tile message of the layers, empty layers are left out as the spec recommends
+func Encode(layers ...*Layer) []byte {
+	var tile []byte
+	for _, l := range layers {
+		if len(l.features) == 0 {
+			continue
+		}
+		var layer []byte
+		layer = protowire.AppendTag(layer, 15, protowire.VarintType)
+		layer = protowire.AppendVarint(layer, version)
+		layer = protowire.AppendTag(layer, 1, protowire.BytesType)
+		layer = protowire.AppendString(layer, l.name)
+		for _, feature := range l.features {
+			layer = protowire.AppendTag(layer, 2, protowire.BytesType)
+			layer = protowire.AppendBytes(layer, feature)
+		}
+		for _, key := range l.keys {
+			layer = protowire.AppendTag(layer, 3, protowire.BytesType)
+			layer = protowire.AppendString(layer, key)
+		}
+		for _, value := range l.values {
+			layer = protowire.AppendTag(layer, 4, protowire.BytesType)
+			layer = protowire.AppendBytes(layer, value)
+		}
+		layer = protowire.AppendTag(layer, 5, protowire.VarintType)
+		layer = protowire.AppendVarint(layer, uint64(l.extent))
+
+		tile = protowire.AppendTag(tile, tileLayers, protowire.BytesType)
+		tile = protowire.AppendBytes(tile, layer)
+	}
+	return tile
+}
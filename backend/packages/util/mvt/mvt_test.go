@@ -0,0 +1,99 @@
+package mvt
+
+import (
+	"google.golang.org/protobuf/encoding/protowire"
+	"testing"
+)
+
+// fields decodes a message to its fields by number, bytes fields as []byte and the others as uint64
+func fields(t *testing.T, b []byte) map[protowire.Number][]any {
+	ret := make(map[protowire.Number][]any)
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		if n < 0 {
+			t.Fatal(protowire.ParseError(n))
+		}
+		b = b[n:]
+		var v any
+		switch typ {
+		case protowire.VarintType:
+			v, n = protowire.ConsumeVarint(b)
+		case protowire.BytesType:
+			v, n = protowire.ConsumeBytes(b)
+		case protowire.Fixed64Type:
+			v, n = protowire.ConsumeFixed64(b)
+		default:
+			t.Fatalf("unexpected wire type %v", typ)
+		}
+		if n < 0 {
+			t.Fatal(protowire.ParseError(n))
+		}
+		b = b[n:]
+		ret[num] = append(ret[num], v)
+	}
+	return ret
+}
+
+func packed(t *testing.T, b []byte) []uint64 {
+	var ret []uint64
+	for len(b) > 0 {
+		v, n := protowire.ConsumeVarint(b)
+		if n < 0 {
+			t.Fatal(protowire.ParseError(n))
+		}
+		ret = append(ret, v)
+		b = b[n:]
+	}
+	return ret
+}
+
+func TestEncode(t *testing.T) {
+	layer := NewLayer("facilities", DefaultExtent)
+	if err := layer.AddPoint(42, 10, -3, map[string]any{"name": "Anzu", "open": true}); err != nil {
+		t.Fatal(err)
+	}
+	if err := layer.AddPoint(0, 4095, 0, map[string]any{"name": "Anzu"}); err != nil {
+		t.Fatal(err)
+	}
+	tile := fields(t, Encode(layer, NewLayer("empty", DefaultExtent)))
+	if len(tile[tileLayers]) != 1 {
+		t.Fatalf("expect only the non empty layer, got %d", len(tile[tileLayers]))
+	}
+
+	l := fields(t, tile[tileLayers][0].([]byte))
+	if l[15][0] != uint64(2) || string(l[1][0].([]byte)) != "facilities" || l[5][0] != uint64(DefaultExtent) {
+		t.Fatalf("unexpected layer header %v", l)
+	}
+	if len(l[3]) != 2 || string(l[3][0].([]byte)) != "name" || string(l[3][1].([]byte)) != "open" {
+		t.Fatalf("expect keys sorted and shared, got %v", l[3])
+	}
+	if len(l[4]) != 2 {
+		t.Fatalf("expect the name value shared, got %d values", len(l[4]))
+	}
+
+	features := l[2]
+	if len(features) != 2 {
+		t.Fatalf("expect 2 features, got %d", len(features))
+	}
+	first := fields(t, features[0].([]byte))
+	if first[1][0] != uint64(42) || first[3][0] != uint64(geomPoint) {
+		t.Fatalf("unexpected feature %v", first)
+	}
+	if tags := packed(t, first[2][0].([]byte)); len(tags) != 4 || tags[0] != 0 || tags[1] != 0 || tags[2] != 1 || tags[3] != 1 {
+		t.Fatalf("unexpected tags %v", tags)
+	}
+	geometry := packed(t, first[4][0].([]byte))
+	if len(geometry) != 3 || geometry[0] != 9 ||
+		protowire.DecodeZigZag(geometry[1]) != 10 || protowire.DecodeZigZag(geometry[2]) != -3 {
+		t.Fatalf("unexpected geometry %v", geometry)
+	}
+	if second := fields(t, features[1].([]byte)); len(second[1]) != 0 {
+		t.Fatalf("expect no id, got %v", second[1])
+	}
+}
+
+func TestAddPoint_UnsupportedValue(t *testing.T) {
+	if err := NewLayer("facilities", DefaultExtent).AddPoint(1, 0, 0, map[string]any{"items": []string{"tacos"}}); err == nil {
+		t.Fatal("expect error for a slice property")
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"golang.org/x/exp/constraints"
 	"golang.org/x/sync/errgroup"
 	"strings"
+	"sync"
 	"time"
 )
 
@@ -28,7 +29,7 @@ type Client[K constraints.Ordered] struct {
 func NewClient[K constraints.Ordered](config Config) *Client[K] {
 	return &Client[K]{
 		Config: config,
-		Client: redis.NewUniversalClient(&redis.UniversalOptions{Addrs: strings.Split(config.Addr, ",")}),
+		Client: newUniversalClient(config),
 	}
 }
 
@@ -55,6 +56,54 @@ func (c *Client[K]) del(ctx context.Context, namespace string, id K) error {
 	return err
 }
 
+// keys scans ids stored in namespace, keys may be hash tagged, see tag
+func (c *Client[K]) keys(ctx context.Context, namespace string) ([]K, error) {
+	prefix := fmt.Sprintf("%s:%s:", c.Prefix, namespace)
+	untag := strings.NewReplacer("{", "", "}", "")
+	var ids []K
+	for _, pattern := range []string{prefix + "*", "{" + prefix + "*"} {
+		keys, err := scanKeys(ctx, c.Client, pattern)
+		if err != nil {
+			return nil, err
+		}
+		for _, key := range keys {
+			id, err := keyFromStar[K](strings.TrimPrefix(untag.Replace(key), prefix))
+			if err != nil {
+				return nil, err
+			}
+			ids = append(ids, id)
+		}
+	}
+	return ids, nil
+}
+
+// scanKeys returns keys matching pattern, of every master if client is a cluster
+func scanKeys(ctx context.Context, client redis.UniversalClient, pattern string) ([]string, error) {
+	cluster, ok := client.(*redis.ClusterClient)
+	if !ok {
+		return scanNode(ctx, client, pattern)
+	}
+	var mu sync.Mutex
+	var keys []string
+	err := cluster.ForEachMaster(ctx, func(ctx context.Context, node *redis.Client) error {
+		nodeKeys, err := scanNode(ctx, node, pattern)
+		mu.Lock()
+		defer mu.Unlock()
+		keys = append(keys, nodeKeys...)
+		return err
+	})
+	return keys, wrapUnavailable(err)
+}
+
+func scanNode(ctx context.Context, client redis.Cmdable, pattern string) ([]string, error) {
+	var keys []string
+	iter := client.Scan(ctx, 0, pattern, 1000).Iterator()
+	for iter.Next(ctx) {
+		keys = append(keys, iter.Val())
+	}
+	return keys, iter.Err()
+}
+
 func (c *Client[K]) mGet(ctx context.Context, namespace string, ids []K) ([]string, []K, error) {
 	g, ctx := errgroup.WithContext(ctx)
 	ret := safeslice.NewSafeSlice[string]()
@@ -49,6 +49,11 @@ func (c *EntityStore[K, V]) Del(ctx context.Context, ids ...K) error {
 	return c.client.mDel(ctx, c.namespace, ids)
 }
 
+// Keys returns the key of every stored entity, in no order
+func (c *EntityStore[K, V]) Keys(ctx context.Context) ([]K, error) {
+	return c.client.keys(ctx, c.namespace)
+}
+
 func (c *EntityStore[K, V]) Get(ctx context.Context, keys []K) ([]V, error) {
 	return c.getFetchSet(ctx, keys, false, nil)
 }
@@ -96,11 +101,11 @@ func (c *EntityStore[K, V]) get(ctx context.Context, keys []K) (gGetResult[K, V]
 	ret := gGetResult[K, V]{}
 	items, missed, err := c.client.mGet(ctx, c.namespace, keys)
 	if err != nil {
-		return ret, nil
+		return ret, err
 	}
 	vals, err := mFromStr[V](items)
 	if err != nil {
-		return ret, nil
+		return ret, err
 	}
 	ret.Missed = missed
 	ret.Values = vals
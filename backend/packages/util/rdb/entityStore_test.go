@@ -99,3 +99,19 @@ func fakeEntityStoreIDs(count int) []int {
 	}
 	return ret
 }
+
+func TestEntityStore_Keys(t *testing.T) {
+	ctx := context.Background()
+	entityStore := NewEntityStore[int, EntityStorePost]("TestEntityStoreKeys", time.Hour, getConfig()).
+		WithGetKey(EntityStorePostID)
+	if err := entityStore.Set(ctx, fakeEntityStorePost(3)); err != nil {
+		t.Fatal(err)
+	}
+	keys, err := entityStore.Keys(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !lo.Every(keys, fakeEntityStoreIDs(3)) {
+		t.Fatalf("expect the keys set, got %v", keys)
+	}
+}
@@ -3,9 +3,12 @@ package rdb
 import (
 	"context"
 	"fmt"
+	"food-trucks/packages/util/errs"
+	"food-trucks/packages/util/geo"
+	"github.com/google/uuid"
 	"github.com/redis/go-redis/v9"
+	"github.com/samber/lo"
 	"golang.org/x/exp/constraints"
-	"strings"
 	"time"
 )
 
@@ -17,6 +20,7 @@ type GeoStore[K constraints.Ordered, Entity any] struct {
 	entityStore  Cacheable[K, Entity]
 	getMemberKey func(Entity) K
 	getLocation  func(Entity) (float64, float64)
+	sliceStore   *SliceStore[K, Entity]
 }
 
 func NewGeoStore[MemberKey constraints.Ordered, Entity any](
@@ -27,7 +31,7 @@ func NewGeoStore[MemberKey constraints.Ordered, Entity any](
 ) *GeoStore[MemberKey, Entity] {
 	return &GeoStore[MemberKey, Entity]{
 		Config:      config,
-		client:      redis.NewUniversalClient(&redis.UniversalOptions{Addrs: strings.Split(config.Addr, ",")}),
+		client:      newUniversalClient(config),
 		namespace:   namespace,
 		entityStore: entityStore,
 		duration:    duration,
@@ -44,6 +48,12 @@ func (s *GeoStore[K, V]) WithGetLocation(f func(V) (lat float64, lon float64)) *
 	return s
 }
 
+// WithSliceStore enables GetNearestInSlice, the slice store should hold the same members
+func (s *GeoStore[K, V]) WithSliceStore(sliceStore *SliceStore[K, V]) *GeoStore[K, V] {
+	s.sliceStore = sliceStore
+	return s
+}
+
 func (s *GeoStore[K, V]) Add(ctx context.Context, item V) error {
 	lat, lon := s.getLocation(item)
 	key := fmt.Sprintf("%v", s.getMemberKey(item))
@@ -55,12 +65,54 @@ func (s *GeoStore[K, V]) Add(ctx context.Context, item V) error {
 	return err
 }
 
+// AddAll adds or moves many items with one GEOADD
+func (s *GeoStore[K, V]) AddAll(ctx context.Context, items []V) error {
+	if len(items) == 0 {
+		return nil
+	}
+	locations := make([]*redis.GeoLocation, len(items))
+	for i, item := range items {
+		lat, lon := s.getLocation(item)
+		locations[i] = &redis.GeoLocation{
+			Name:      fmt.Sprintf("%v", s.getMemberKey(item)),
+			Longitude: lon,
+			Latitude:  lat,
+		}
+	}
+	_, err := s.client.GeoAdd(ctx, s.namespace, locations...).Result()
+	return err
+}
+
+func (s *GeoStore[K, V]) Del(ctx context.Context, ids ...K) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	_, err := s.client.ZRem(ctx, s.namespace, lo.ToAnySlice(ids)...).Result()
+	return err
+}
+
+// Keys returns the key of every member, in no order
+func (s *GeoStore[K, V]) Keys(ctx context.Context) ([]K, error) {
+	members, err := s.client.ZRange(ctx, s.namespace, 0, -1).Result()
+	if err != nil {
+		return nil, err
+	}
+	keys := make([]K, len(members))
+	for i, member := range members {
+		if keys[i], err = keyFromStar[K](member); err != nil {
+			return nil, errs.Err(err)
+		}
+	}
+	return keys, nil
+}
+
 func (s *GeoStore[K, V]) Get(ctx context.Context, lat float64, lon float64, radius float64) ([]V, error) {
 	res, err := s.client.GeoRadius(ctx, s.namespace, lon, lat, &redis.GeoRadiusQuery{
 		Radius:    radius,
 		Unit:      "km",
 		WithCoord: true,
 		WithDist:  true,
+		Sort:      "ASC",
 	}).Result()
 	if err != nil {
 		return nil, err
@@ -75,3 +127,150 @@ func (s *GeoStore[K, V]) Get(ctx context.Context, lat float64, lon float64, radi
 	}
 	return s.entityStore.Get(ctx, keys)
 }
+
+// maxRadius is half of the earth's circumference in km, a circle of it covers the whole earth
+const maxRadius = 20038.0
+
+/*
+GetNearest returns at most count entities nearest to the point first, with distances in meters.
+radius is in km, radius <= 0 means no limit, count <= 0 means no limit.
+*/
+func (s *GeoStore[K, V]) GetNearest(ctx context.Context, lat float64, lon float64, radius float64, count int) ([]V, []float64, error) {
+	if radius <= 0 {
+		radius = maxRadius
+	}
+	res, err := s.client.GeoSearchLocation(ctx, s.namespace, &redis.GeoSearchLocationQuery{
+		GeoSearchQuery: redis.GeoSearchQuery{
+			Longitude:  lon,
+			Latitude:   lat,
+			Radius:     radius * 1000,
+			RadiusUnit: "m",
+			Sort:       "ASC",
+			Count:      max(count, 0),
+		},
+		WithDist: true,
+	}).Result()
+	if err != nil {
+		return nil, nil, err
+	}
+	return s.getEntities(ctx, res)
+}
+
+/*
+GetNearestInSlice is GetNearest limited to members of a slice of the slice store.
+The geo set and the slice zset are intersected into a temporary zset keeping the geo scores (geohashes),
+so GEOSEARCH can run on it, all in one transaction.
+*/
+func (s *GeoStore[K, V]) GetNearestInSlice(ctx context.Context, sliceID any, lat float64, lon float64, radius float64, count int) ([]V, []float64, error) {
+	if s.sliceStore == nil {
+		return nil, nil, fmt.Errorf("geo store %s has no slice store", s.namespace)
+	}
+	if radius <= 0 {
+		radius = maxRadius
+	}
+	tmpKey := fmt.Sprintf("%s:%s:tmp:%s", s.Prefix, s.namespace, uuid.NewString())
+	p := s.client.TxPipeline()
+	p.ZInterStore(ctx, tmpKey, &redis.ZStore{
+		Keys:    []string{s.namespace, s.sliceStore.sliceKey(sliceID)},
+		Weights: []float64{1, 0},
+	})
+	searchCmd := p.GeoSearchLocation(ctx, tmpKey, &redis.GeoSearchLocationQuery{
+		GeoSearchQuery: redis.GeoSearchQuery{
+			Longitude:  lon,
+			Latitude:   lat,
+			Radius:     radius * 1000,
+			RadiusUnit: "m",
+			Sort:       "ASC",
+			Count:      max(count, 0),
+		},
+		WithDist: true,
+	})
+	p.Del(ctx, tmpKey)
+	if _, err := p.Exec(ctx); IgnoreNoKey(err) != nil {
+		return nil, nil, err
+	}
+	return s.getEntities(ctx, searchCmd.Val())
+}
+
+// getEntities keeps the order of locations, locations whose entity is missing are dropped
+func (s *GeoStore[K, V]) getEntities(ctx context.Context, locations []redis.GeoLocation) ([]V, []float64, error) {
+	var keys []K
+	for _, location := range locations {
+		k, err := keyFromStar[K](location.Name)
+		if err != nil {
+			return nil, nil, err
+		}
+		keys = append(keys, k)
+	}
+	items, err := s.entityStore.Get(ctx, keys)
+	if err != nil {
+		return nil, nil, err
+	}
+	byKey := make(map[K]V, len(items))
+	for _, item := range items {
+		byKey[s.getMemberKey(item)] = item
+	}
+	ret := make([]V, 0, len(keys))
+	dists := make([]float64, 0, len(keys))
+	for i, k := range keys {
+		if item, ok := byKey[k]; ok {
+			ret = append(ret, item)
+			dists = append(dists, locations[i].Dist)
+		}
+	}
+	return ret, dists, nil
+}
+
+/*
+GetInBox returns at most count entities inside the lat/lon box, nearest to the box center first, count <= 0 means no limit.
+GEOSEARCH BYBOX measures width along parallels, so the searched box is widened to cover the lat/lon box
+at its widest latitude, then results are trimmed to the exact box.
+As the widened box has members outside the exact one, a COUNT which leaves fewer than count after trimming
+is retried with a doubled COUNT.
+*/
+func (s *GeoStore[K, V]) GetInBox(ctx context.Context, minLat, minLon, maxLat, maxLon float64, count int) ([]V, error) {
+	centerLat, centerLon := (minLat+maxLat)/2, (minLon+maxLon)/2
+	query := redis.GeoSearchQuery{
+		Longitude: centerLon,
+		Latitude:  centerLat,
+		Sort:      "ASC",
+	}
+	if maxLon-minLon < 180 {
+		widestLat := 0.0
+		if minLat > 0 {
+			widestLat = minLat
+		} else if maxLat < 0 {
+			widestLat = maxLat
+		}
+		query.BoxWidth = 2*geo.Distance(widestLat, centerLon, widestLat, minLon) + 1
+		query.BoxHeight = geo.Distance(minLat, centerLon, maxLat, centerLon) + 1
+		query.BoxUnit = "m"
+	} else {
+		query.Radius = maxRadius
+		query.RadiusUnit = "km"
+	}
+	var inBox []redis.GeoLocation
+	for query.Count = max(count, 0); ; query.Count *= 2 {
+		res, err := s.client.GeoSearchLocation(ctx, s.namespace, &redis.GeoSearchLocationQuery{
+			GeoSearchQuery: query,
+			WithCoord:      true,
+		}).Result()
+		if err != nil {
+			return nil, err
+		}
+		inBox = inBox[:0]
+		for _, re := range res {
+			if re.Latitude >= minLat && re.Latitude <= maxLat && re.Longitude >= minLon && re.Longitude <= maxLon {
+				inBox = append(inBox, re)
+			}
+		}
+		if query.Count == 0 || len(inBox) >= count || len(res) < query.Count {
+			break
+		}
+	}
+	if count > 0 && len(inBox) > count {
+		inBox = inBox[:count]
+	}
+	items, _, err := s.getEntities(ctx, inBox)
+	return items, err
+}
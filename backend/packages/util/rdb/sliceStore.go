@@ -29,7 +29,7 @@ func NewSliceStore[MemberKey constraints.Ordered, Entity any](
 ) *SliceStore[MemberKey, Entity] {
 	return &SliceStore[MemberKey, Entity]{
 		Config:      config,
-		client:      redis.NewUniversalClient(&redis.UniversalOptions{Addrs: strings.Split(config.Addr, ",")}),
+		client:      newUniversalClient(config),
 		namespace:   namespace,
 		entityStore: entityStore,
 		duration:    duration,
@@ -52,6 +52,18 @@ func (s *SliceStore[K, V]) DelSlice(ctx context.Context, sliceID any) error {
 	return err
 }
 
+// SliceIDs returns the id of every stored slice, in no order
+func (s *SliceStore[K, V]) SliceIDs(ctx context.Context) ([]string, error) {
+	prefix := s.sliceKey("")
+	keys, err := scanKeys(ctx, s.client, prefix+"*")
+	if err != nil {
+		return nil, err
+	}
+	return lo.Map(keys, func(key string, _ int) string {
+		return strings.TrimPrefix(key, prefix)
+	}), nil
+}
+
 func (s *SliceStore[K, V]) DelMember(ctx context.Context, sliceID any, memberKeys []K) error {
 	key := s.sliceKey(sliceID)
 	_, err := s.client.ZRem(ctx, key, lo.ToAnySlice(memberKeys)...).Result()
@@ -74,6 +86,29 @@ func (s *SliceStore[K, Entity]) AddMem(ctx context.Context, sliceID any, items [
 	return err
 }
 
+/*
+AddMembers adds members to many slices in one pipeline, members is keyed by slice id.
+Unlike AddMem it doesn't set entities, they should be set to the entity store separately.
+*/
+func (s *SliceStore[K, Entity]) AddMembers(ctx context.Context, members map[any][]Entity) error {
+	if len(members) == 0 {
+		return nil
+	}
+	p := s.client.Pipeline()
+	for sliceID, items := range members {
+		zs := make([]redis.Z, len(items))
+		for i, item := range items {
+			zs[i] = redis.Z{
+				Score:  s.getScore(item),
+				Member: s.getMemberKey(item),
+			}
+		}
+		p.ZAdd(ctx, s.sliceKey(sliceID), zs...)
+	}
+	_, err := p.Exec(ctx)
+	return err
+}
+
 func (s *SliceStore[K, Entity]) GetAllMemberEntities(ctx context.Context, sliceID any) ([]Entity, error) {
 	option := &redis.ZRangeBy{
 		Min: "-inf",
@@ -93,6 +128,27 @@ func (s *SliceStore[K, Entity]) GetAllMemberEntities(ctx context.Context, sliceI
 	return items, nil
 }
 
+// GetMemberEntities is a page of GetAllMemberEntities with LIMIT, count <= 0 means all members after offset
+func (s *SliceStore[K, Entity]) GetMemberEntities(ctx context.Context, sliceID any, offset int, count int) ([]Entity, error) {
+	if count <= 0 {
+		count = -1
+	}
+	members, err := s.client.ZRevRangeByScore(ctx, s.sliceKey(sliceID), &redis.ZRangeBy{
+		Min:    "-inf",
+		Max:    "+inf",
+		Offset: int64(max(offset, 0)),
+		Count:  int64(count),
+	}).Result()
+	if IgnoreNoKey(err) != nil {
+		return nil, errs.Err(err)
+	}
+	items, err := s.getEntities(ctx, members)
+	if err != nil {
+		return nil, errs.Err(err)
+	}
+	return items, nil
+}
+
 func (s *SliceStore[K, V]) getEntities(ctx context.Context, members []string) ([]V, error) {
 	var memberKeys []K
 	for _, mem := range members {
@@ -0,0 +1,80 @@
+package rdb
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+)
+
+type SliceStorePost struct {
+	ID    string
+	Score float64
+}
+
+func SliceStorePostID(p SliceStorePost) string {
+	return p.ID
+}
+
+func SliceStorePostScore(p SliceStorePost) float64 {
+	return p.Score
+}
+
+func TestSliceStore_GetMemberEntities(t *testing.T) {
+	ctx := context.Background()
+	entityStore := NewEntityStore[string, SliceStorePost]("TestSliceStorePost", time.Hour, getConfig()).
+		WithGetKey(SliceStorePostID)
+	store := NewSliceStore[string, SliceStorePost]("TestSliceStore", time.Hour, getConfig(), entityStore).
+		WithGetKey(SliceStorePostID).WithGetScore(SliceStorePostScore)
+	if err := store.DelSlice(ctx, "tacos"); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.AddMem(ctx, "tacos", []SliceStorePost{{ID: "a", Score: 1}, {ID: "b", Score: 3}, {ID: "c", Score: 1}}); err != nil {
+		t.Fatal(err)
+	}
+
+	all, err := store.GetMemberEntities(ctx, "tacos", 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(all) != 3 || all[0].ID != "b" || all[1].ID != "c" || all[2].ID != "a" {
+		t.Fatalf("expect score desc then member desc, got %v", all)
+	}
+	page, err := store.GetMemberEntities(ctx, "tacos", 1, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(page) != 1 || page[0].ID != "c" {
+		t.Fatalf("expect the second member, got %v", page)
+	}
+	rest, err := store.GetMemberEntities(ctx, "tacos", 1, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rest) != 2 || rest[0].ID != "c" || rest[1].ID != "a" {
+		t.Fatalf("expect all members after the first, got %v", rest)
+	}
+}
+
+func TestSliceStore_SliceIDs(t *testing.T) {
+	ctx := context.Background()
+	entityStore := NewEntityStore[string, SliceStorePost]("TestSliceStorePost", time.Hour, getConfig()).
+		WithGetKey(SliceStorePostID)
+	store := NewSliceStore[string, SliceStorePost]("TestSliceStoreIDs", time.Hour, getConfig(), entityStore).
+		WithGetKey(SliceStorePostID).WithGetScore(SliceStorePostScore)
+	if err := store.AddMem(ctx, "Tacos", []SliceStorePost{{ID: "a", Score: 1}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.AddMem(ctx, "tacos", []SliceStorePost{{ID: "a", Score: 1}}); err != nil {
+		t.Fatal(err)
+	}
+	ids, err := store.SliceIDs(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sort.Strings(ids)
+	if !reflect.DeepEqual(ids, []string{"Tacos", "tacos"}) {
+		t.Fatalf("expect both slices, got %v", ids)
+	}
+}
@@ -0,0 +1,69 @@
+package rdb
+
+import (
+	"context"
+	"errors"
+	"food-trucks/packages/util/errs"
+	"github.com/redis/go-redis/v9"
+	"io"
+	"net"
+	"strings"
+)
+
+// newUniversalClient is the redis client of every store, errors reaching redis are errs.KindUnavailable
+func newUniversalClient(config Config) redis.UniversalClient {
+	client := redis.NewUniversalClient(&redis.UniversalOptions{Addrs: strings.Split(config.Addr, ",")})
+	client.AddHook(unavailableHook{})
+	return client
+}
+
+// unavailableHook wraps network errors and timeouts of commands, so the web app responds 503 instead of 500
+type unavailableHook struct{}
+
+func (unavailableHook) DialHook(next redis.DialHook) redis.DialHook {
+	return next
+}
+
+func (unavailableHook) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		err := wrapUnavailable(next(ctx, cmd))
+		if err != nil {
+			// commands return the error they keep, not the one of the hook
+			cmd.SetErr(err)
+		}
+		return err
+	}
+}
+
+func (unavailableHook) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		err := wrapUnavailable(next(ctx, cmds))
+		for _, cmd := range cmds {
+			if cmdErr := cmd.Err(); cmdErr != nil {
+				cmd.SetErr(wrapUnavailable(cmdErr))
+			}
+		}
+		return err
+	}
+}
+
+// wrapUnavailable marks err unavailable if redis couldn't be reached or didn't answer in time, others are kept
+func wrapUnavailable(err error) error {
+	if err == nil || errs.KindOf(err) == errs.KindUnavailable {
+		return err
+	}
+	var netErr net.Error
+	switch {
+	case errors.As(err, &netErr),
+		errors.Is(err, io.EOF),
+		errors.Is(err, io.ErrUnexpectedEOF),
+		errors.Is(err, redis.ErrClosed),
+		errors.Is(err, context.DeadlineExceeded),
+		strings.HasPrefix(err.Error(), "LOADING "),
+		strings.HasPrefix(err.Error(), "CLUSTERDOWN "),
+		err.Error() == "redis: connection pool timeout":
+		return errs.Unavailable("redis unavailable, %w", err)
+	default:
+		return err
+	}
+}
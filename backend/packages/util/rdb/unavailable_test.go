@@ -0,0 +1,42 @@
+package rdb
+
+import (
+	"context"
+	"errors"
+	"food-trucks/packages/util/errs"
+	"github.com/redis/go-redis/v9"
+	"testing"
+	"time"
+)
+
+func TestWrapUnavailable(t *testing.T) {
+	ctx := context.Background()
+	// nothing listens on port 1
+	config := Config{Addr: "127.0.0.1:1", Prefix: "Test"}
+	entityStore := NewEntityStore[int, EntityStorePost](TestEntityStore, time.Hour, config).
+		WithGetKey(EntityStorePostID)
+	if err := entityStore.Set(ctx, fakeEntityStorePost(1)); errs.KindOf(err) != errs.KindUnavailable {
+		t.Fatalf("expect unavailable, got %v", err)
+	}
+	if _, err := entityStore.Get(ctx, []int{1}); errs.KindOf(err) != errs.KindUnavailable {
+		t.Fatalf("expect reads unavailable, got %v", err)
+	}
+	_, err, _ := entityStore.GetFetchSet(ctx, []int{1}, func(ids []int) ([]EntityStorePost, error) {
+		t.Fatal("expect no fetch when redis can't be read")
+		return nil, nil
+	})
+	if errs.KindOf(err) != errs.KindUnavailable {
+		t.Fatalf("expect fetch set unavailable, got %v", err)
+	}
+	sliceStore := NewSliceStore[int, EntityStorePost]("TestSliceStore", time.Hour, config, entityStore)
+	if _, err := sliceStore.GetMemberEntities(ctx, "tacos", 0, 1); errs.KindOf(err) != errs.KindUnavailable {
+		t.Fatalf("expect unavailable, got %v", err)
+	}
+
+	if err := wrapUnavailable(redis.Nil); err != redis.Nil {
+		t.Fatalf("expect redis.Nil kept for IgnoreNoKey, got %v", err)
+	}
+	if err := wrapUnavailable(errors.New("WRONGTYPE Operation against a key")); errs.KindOf(err) != errs.KindInternal {
+		t.Fatalf("expect command errors internal, got %v", err)
+	}
+}
@@ -0,0 +1,201 @@
+package search
+
+import (
+	"golang.org/x/exp/constraints"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+)
+
+const (
+	// bm25 parameters, k1 saturates repeated terms, b normalizes by document length
+	k1 = 1.2
+	b  = 0.75
+
+	exactWeight = 1.0
+	// prefix matches rank below exact ones, longer prefixes rank higher
+	prefixWeight = 0.6
+	// typo matches rank below exact ones, per edit
+	fuzzyWeight = 0.3
+)
+
+type Hit[K constraints.Ordered] struct {
+	Key   K
+	Score float64
+}
+
+/*
+Index is an in memory inverted index from stemmed terms to documents.
+A query term matches terms equal to it, starting with it, or within 1-2 typos of it;
+documents are ranked by bm25, weighted by how each term matched and how many query terms matched.
+*/
+type Index[K constraints.Ordered] struct {
+	mu       sync.RWMutex
+	postings map[string]map[K]int
+	docs     map[K][]string
+	totalLen int
+	// vocab is the sorted terms, for prefix search
+	vocab []string
+}
+
+func NewIndex[K constraints.Ordered]() *Index[K] {
+	return &Index[K]{
+		postings: make(map[string]map[K]int),
+		docs:     make(map[K][]string),
+	}
+}
+
+// Add indexes texts as one document, an existing document with the same key is replaced
+func (idx *Index[K]) Add(key K, texts ...string) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	idx.remove(key)
+	var terms []string
+	for _, text := range texts {
+		terms = append(terms, Terms(text)...)
+	}
+	idx.docs[key] = terms
+	idx.totalLen += len(terms)
+	for _, term := range terms {
+		docs, ok := idx.postings[term]
+		if !ok {
+			docs = make(map[K]int)
+			idx.postings[term] = docs
+			i := sort.SearchStrings(idx.vocab, term)
+			idx.vocab = append(idx.vocab[:i], append([]string{term}, idx.vocab[i:]...)...)
+		}
+		docs[key]++
+	}
+}
+
+func (idx *Index[K]) Remove(key K) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	idx.remove(key)
+}
+
+func (idx *Index[K]) remove(key K) {
+	terms, ok := idx.docs[key]
+	if !ok {
+		return
+	}
+	for _, term := range terms {
+		// a repeated term may already be gone
+		docs, ok := idx.postings[term]
+		if !ok {
+			continue
+		}
+		delete(docs, key)
+		if len(docs) == 0 {
+			delete(idx.postings, term)
+			i := sort.SearchStrings(idx.vocab, term)
+			idx.vocab = append(idx.vocab[:i], idx.vocab[i+1:]...)
+		}
+	}
+	idx.totalLen -= len(terms)
+	delete(idx.docs, key)
+}
+
+func (idx *Index[K]) Len() int {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+	return len(idx.docs)
+}
+
+// Search returns documents matching any query term, best first, limit <= 0 means no limit
+func (idx *Index[K]) Search(query string, limit int) []Hit[K] {
+	tokens := Tokenize(query)
+	if len(tokens) == 0 {
+		return nil
+	}
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+	scores := make(map[K]float64)
+	matched := make(map[K]int)
+	for _, token := range tokens {
+		best := make(map[K]float64)
+		for term, weight := range idx.matchTerms(token) {
+			idf := idx.idf(term)
+			for key, tf := range idx.postings[term] {
+				score := weight * idf * idx.tfWeight(tf, len(idx.docs[key]))
+				best[key] = math.Max(best[key], score)
+			}
+		}
+		for key, score := range best {
+			scores[key] += score
+			matched[key]++
+		}
+	}
+
+	hits := make([]Hit[K], 0, len(scores))
+	for key, score := range scores {
+		coverage := float64(matched[key]) / float64(len(tokens))
+		hits = append(hits, Hit[K]{Key: key, Score: score * coverage})
+	}
+	sort.Slice(hits, func(i, j int) bool {
+		if hits[i].Score != hits[j].Score {
+			return hits[i].Score > hits[j].Score
+		}
+		return hits[i].Key < hits[j].Key
+	})
+	if limit > 0 && len(hits) > limit {
+		hits = hits[:limit]
+	}
+	return hits
+}
+
+// matchTerms returns indexed terms matching the query token with their match weights
+func (idx *Index[K]) matchTerms(token string) map[string]float64 {
+	stem := Stem(token)
+	ret := make(map[string]float64)
+	if _, ok := idx.postings[stem]; ok {
+		ret[stem] = exactWeight
+	}
+
+	// prefix, the raw token is used since a stem of a partial word is meaningless
+	from := sort.SearchStrings(idx.vocab, token)
+	for _, term := range idx.vocab[from:] {
+		if !strings.HasPrefix(term, token) {
+			break
+		}
+		if term != stem {
+			ret[term] = math.Max(ret[term], prefixWeight*(0.5+0.5*float64(len(token))/float64(len(term))))
+		}
+	}
+
+	if maxEdits := allowedEdits(stem); maxEdits > 0 {
+		for _, term := range idx.vocab {
+			if _, ok := ret[term]; ok {
+				continue
+			}
+			if d := editDistance(stem, term, maxEdits); d <= maxEdits {
+				ret[term] = exactWeight - fuzzyWeight*float64(d)
+			}
+		}
+	}
+	return ret
+}
+
+// allowedEdits is how many typos a term of this length tolerates
+func allowedEdits(term string) int {
+	switch n := len([]rune(term)); {
+	case n < 4:
+		return 0
+	case n < 8:
+		return 1
+	default:
+		return 2
+	}
+}
+
+func (idx *Index[K]) idf(term string) float64 {
+	n, df := float64(len(idx.docs)), float64(len(idx.postings[term]))
+	return math.Log(1 + (n-df+0.5)/(df+0.5))
+}
+
+func (idx *Index[K]) tfWeight(tf int, docLen int) float64 {
+	avgLen := float64(idx.totalLen) / float64(len(idx.docs))
+	f := float64(tf)
+	return f * (k1 + 1) / (f + k1*(1-b+b*float64(docLen)/avgLen))
+}
@@ -0,0 +1,73 @@
+package search
+
+import (
+	"testing"
+)
+
+func newTestIndex() *Index[string] {
+	idx := NewIndex[string]()
+	idx.Add("taqueria", "Tacos", "Burritos", "Quesadillas")
+	idx.Add("cold", "Cold Truck", "Sandwiches", "Burritos", "Candy", "Chips", "Soda", "Water", "Coffee", "Muffins")
+	idx.Add("pizza", "Pizza", "Salads")
+	idx.Add("burger", "Burgers", "Fries")
+	return idx
+}
+
+func keys(hits []Hit[string]) []string {
+	var ret []string
+	for _, hit := range hits {
+		ret = append(ret, hit.Key)
+	}
+	return ret
+}
+
+func TestIndex_Search(t *testing.T) {
+	idx := newTestIndex()
+	cases := []struct {
+		query string
+		first string
+		count int
+	}{
+		{"Taco", "taqueria", 1},
+		{"burrito", "taqueria", 2},
+		{"burito", "taqueria", 2},
+		{"bur", "burger", 3},
+		{"sandwich", "cold", 1},
+		{"sushi", "", 0},
+	}
+	for _, c := range cases {
+		hits := idx.Search(c.query, 0)
+		if len(hits) != c.count || (c.count > 0 && hits[0].Key != c.first) {
+			t.Fatalf("search %q expect %d hits led by %q, got %v", c.query, c.count, c.first, hits)
+		}
+	}
+}
+
+func TestIndex_SearchRanksCoverage(t *testing.T) {
+	idx := newTestIndex()
+	hits := idx.Search("burritos fries", 0)
+	if len(hits) != 3 {
+		t.Fatalf("expect taqueria, cold and burger, got %v", keys(hits))
+	}
+	if hits := idx.Search("tacos burritos", 0); hits[0].Key != "taqueria" {
+		t.Fatalf("expect taqueria matching both terms first, got %v", keys(hits))
+	}
+}
+
+func TestIndex_Remove(t *testing.T) {
+	idx := newTestIndex()
+	idx.Remove("taqueria")
+	if hits := idx.Search("tacos", 0); len(hits) != 0 {
+		t.Fatalf("expect no tacos left, got %v", hits)
+	}
+	idx.Add("cold", "Tacos")
+	if hits := idx.Search("burritos", 0); len(hits) != 0 {
+		t.Fatalf("expect replaced document to drop burritos, got %v", hits)
+	}
+	if hits := idx.Search("tacos", 0); len(hits) != 1 || hits[0].Key != "cold" {
+		t.Fatalf("expect cold to serve tacos, got %v", hits)
+	}
+	if idx.Len() != 3 {
+		t.Fatalf("expect 3 documents, got %d", idx.Len())
+	}
+}
@@ -0,0 +1,144 @@
+package search
+
+import (
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+)
+
+type Suggestion struct {
+	Term  string `json:"term"`
+	Count int    `json:"count"`
+}
+
+// suggestTerm counts the forms of a term, it is displayed as its most frequent form, the first added of a tie
+type suggestTerm struct {
+	display string
+	count   int
+	forms   map[string]int
+	// order is of forms as first added
+	order []string
+}
+
+func (t *suggestTerm) add(form string) {
+	t.count++
+	if _, ok := t.forms[form]; !ok {
+		t.order = append(t.order, form)
+	}
+	t.forms[form]++
+	if t.forms[form] > t.forms[t.display] {
+		t.display = form
+	}
+}
+
+func (t *suggestTerm) remove(form string) {
+	if _, ok := t.forms[form]; !ok {
+		form = t.display
+	}
+	t.count--
+	if t.forms[form]--; t.forms[form] <= 0 {
+		delete(t.forms, form)
+		t.order = slices.DeleteFunc(t.order, func(f string) bool { return f == form })
+	}
+	t.display = ""
+	for _, f := range t.order {
+		if t.forms[f] > t.forms[t.display] {
+			t.display = f
+		}
+	}
+}
+
+// matches tells if any form matches the lowercased prefix
+func (t *suggestTerm) matches(prefix string) bool {
+	for form := range t.forms {
+		if matchPrefix(strings.ToLower(form), prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+/*
+Suggester counts distinct terms for autocomplete. Terms are compared by their stemmed words like Index does,
+so case and plural variants (Tacos, taco) are one term, counted together and displayed as the most frequent form.
+A term matches a prefix at its start or at the start of any of its words.
+*/
+type Suggester struct {
+	mu    sync.RWMutex
+	terms map[string]*suggestTerm
+}
+
+func NewSuggester() *Suggester {
+	return &Suggester{terms: make(map[string]*suggestTerm)}
+}
+
+// suggestKey is the stemmed words of term, empty if it has only stop words
+func suggestKey(term string) string {
+	return strings.Join(Terms(term), " ")
+}
+
+func (s *Suggester) Add(terms ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, term := range terms {
+		key := suggestKey(term)
+		if key == "" {
+			continue
+		}
+		t, ok := s.terms[key]
+		if !ok {
+			t = &suggestTerm{forms: make(map[string]int)}
+			s.terms[key] = t
+		}
+		t.add(strings.Join(strings.Fields(term), " "))
+	}
+}
+
+func (s *Suggester) Remove(terms ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, term := range terms {
+		key := suggestKey(term)
+		if t, ok := s.terms[key]; ok {
+			if t.remove(strings.Join(strings.Fields(term), " ")); t.count <= 0 {
+				delete(s.terms, key)
+			}
+		}
+	}
+}
+
+// Suggest returns terms matching prefix, most popular first, limit <= 0 means no limit
+func (s *Suggester) Suggest(prefix string, limit int) []Suggestion {
+	prefix = strings.ToLower(strings.Join(strings.Fields(prefix), " "))
+	s.mu.RLock()
+	ret := make([]Suggestion, 0)
+	for _, t := range s.terms {
+		if t.matches(prefix) {
+			ret = append(ret, Suggestion{Term: t.display, Count: t.count})
+		}
+	}
+	s.mu.RUnlock()
+	sort.Slice(ret, func(i, j int) bool {
+		if ret[i].Count != ret[j].Count {
+			return ret[i].Count > ret[j].Count
+		}
+		return ret[i].Term < ret[j].Term
+	})
+	if limit > 0 && len(ret) > limit {
+		ret = ret[:limit]
+	}
+	return ret
+}
+
+func matchPrefix(key string, prefix string) bool {
+	if strings.HasPrefix(key, prefix) {
+		return true
+	}
+	for _, word := range Tokenize(key) {
+		if strings.HasPrefix(word, prefix) {
+			return true
+		}
+	}
+	return false
+}
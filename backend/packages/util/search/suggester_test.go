@@ -0,0 +1,63 @@
+package search
+
+import (
+	"testing"
+)
+
+func TestSuggester_Suggest(t *testing.T) {
+	s := NewSuggester()
+	s.Add("Tacos", "Burritos", "Cold  Truck")
+	s.Add("tacos", "Burgers", "Chicken Burrito")
+	s.Add("TACOS", "burritos")
+
+	ret := s.Suggest("bur", 0)
+	if len(ret) != 3 || ret[0].Term != "Burritos" || ret[0].Count != 2 {
+		t.Fatalf("unexpected suggestions %v", ret)
+	}
+	// ties are ordered by term
+	if ret[1].Term != "Burgers" || ret[2].Term != "Chicken Burrito" {
+		t.Fatalf("unexpected order %v", ret)
+	}
+
+	if ret = s.Suggest("TA", 1); len(ret) != 1 || ret[0].Term != "Tacos" || ret[0].Count != 3 {
+		t.Fatalf("unexpected suggestions %v", ret)
+	}
+	if ret = s.Suggest("cold truck", 0); len(ret) != 1 || ret[0].Term != "Cold Truck" {
+		t.Fatalf("unexpected suggestions %v", ret)
+	}
+	if ret = s.Suggest("", 0); len(ret) != 5 {
+		t.Fatalf("empty prefix expect all 5 terms, got %v", ret)
+	}
+
+	s.Remove("Burgers", "burritos")
+	if ret = s.Suggest("bur", 0); len(ret) != 2 || ret[0].Term != "Burritos" || ret[0].Count != 1 {
+		t.Fatalf("unexpected suggestions after remove %v", ret)
+	}
+}
+
+func TestSuggester_Variants(t *testing.T) {
+	s := NewSuggester()
+	s.Add("taco", "Tacos", "Tacos", "Tacos", "Hot dogs", "Hot Dog")
+
+	ret := s.Suggest("taco", 0)
+	if len(ret) != 1 || ret[0].Term != "Tacos" || ret[0].Count != 4 {
+		t.Fatalf("expect variants merged under the most frequent form, got %v", ret)
+	}
+	if ret = s.Suggest("tacos", 0); len(ret) != 1 || ret[0].Count != 4 {
+		t.Fatalf("expect a prefix of any form to match, got %v", ret)
+	}
+	// a tie goes to the first added form
+	if ret = s.Suggest("dog", 0); len(ret) != 1 || ret[0].Term != "Hot dogs" || ret[0].Count != 2 {
+		t.Fatalf("unexpected suggestions %v", ret)
+	}
+
+	s.Remove("Tacos", "Tacos", "Tacos")
+	s.Add("taco")
+	if ret = s.Suggest("ta", 0); len(ret) != 1 || ret[0].Term != "taco" || ret[0].Count != 2 {
+		t.Fatalf("expect the display to follow the most frequent form, got %v", ret)
+	}
+	s.Remove("TACO", "taco")
+	if ret = s.Suggest("ta", 0); len(ret) != 0 {
+		t.Fatalf("expect the term removed with its count, got %v", ret)
+	}
+}
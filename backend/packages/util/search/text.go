@@ -0,0 +1,104 @@
+package search
+
+import (
+	"strings"
+	"unicode"
+)
+
+var stopWords = map[string]bool{
+	"a": true, "and": true, "etc": true, "for": true, "in": true, "of": true, "or": true, "the": true, "with": true,
+}
+
+// Tokenize lowercases s and splits it on anything but letters and digits, stop words are dropped
+func Tokenize(s string) []string {
+	var ret []string
+	for _, token := range strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		if !stopWords[token] {
+			ret = append(ret, token)
+		}
+	}
+	return ret
+}
+
+/*
+Stem is a light english stemmer for menu words, it folds plurals and some spelling variants together,
+e.g. tacos/taco, sandwiches/sandwich, berries/berry, smoothies/smoothie, fries/fry.
+The result isn't always a word (berry -> berri), only equality between stems matters.
+*/
+func Stem(token string) string {
+	switch {
+	case len(token) <= 3:
+	case strings.HasSuffix(token, "sses"):
+		token = token[:len(token)-2]
+	case strings.HasSuffix(token, "ies"):
+		token = token[:len(token)-2]
+	case strings.HasSuffix(token, "es") && hasAnySuffix(token[:len(token)-2], "s", "x", "z", "ch", "sh", "o"):
+		token = token[:len(token)-2]
+	case strings.HasSuffix(token, "s") && !hasAnySuffix(token, "ss", "us", "is"):
+		token = token[:len(token)-1]
+	}
+	switch {
+	case len(token) >= 3 && strings.HasSuffix(token, "y"):
+		token = token[:len(token)-1] + "i"
+	case len(token) > 4 && strings.HasSuffix(token, "e"):
+		token = token[:len(token)-1]
+	}
+	return token
+}
+
+func hasAnySuffix(s string, suffixes ...string) bool {
+	for _, suffix := range suffixes {
+		if strings.HasSuffix(s, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+// Terms tokenizes and stems s
+func Terms(s string) []string {
+	tokens := Tokenize(s)
+	for i, token := range tokens {
+		tokens[i] = Stem(token)
+	}
+	return tokens
+}
+
+/*
+editDistance is the optimal string alignment distance (levenshtein plus adjacent transposition),
+it gives up and returns max+1 once the distance is known to exceed max.
+*/
+func editDistance(a, b string, max int) int {
+	ra, rb := []rune(a), []rune(b)
+	if d := len(ra) - len(rb); d > max || -d > max {
+		return max + 1
+	}
+	prev2 := make([]int, len(rb)+1)
+	prev := make([]int, len(rb)+1)
+	cur := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		cur[0] = i
+		rowMin := cur[0]
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+			if i > 1 && j > 1 && ra[i-1] == rb[j-2] && ra[i-2] == rb[j-1] {
+				cur[j] = min(cur[j], prev2[j-2]+1)
+			}
+			rowMin = min(rowMin, cur[j])
+		}
+		if rowMin > max {
+			return max + 1
+		}
+		prev2, prev, cur = prev, cur, prev2
+	}
+	return prev[len(rb)]
+}
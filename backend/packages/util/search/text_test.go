@@ -0,0 +1,55 @@
+package search
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTokenize(t *testing.T) {
+	tokens := Tokenize("Hot & Cold Drinks: Tacos, Burritos and SODA")
+	expect := []string{"hot", "cold", "drinks", "tacos", "burritos", "soda"}
+	if !reflect.DeepEqual(tokens, expect) {
+		t.Fatalf("expect %v, got %v", expect, tokens)
+	}
+}
+
+func TestStem(t *testing.T) {
+	pairs := [][2]string{
+		{"tacos", "taco"},
+		{"burritos", "burrito"},
+		{"sandwiches", "sandwich"},
+		{"berries", "berry"},
+		{"smoothies", "smoothie"},
+		{"fries", "fry"},
+		{"beverages", "beverage"},
+		{"potatoes", "potato"},
+		{"glasses", "glass"},
+	}
+	for _, pair := range pairs {
+		if a, b := Stem(pair[0]), Stem(pair[1]); a != b {
+			t.Fatalf("expect %s and %s to share a stem, got %s and %s", pair[0], pair[1], a, b)
+		}
+	}
+	if Stem("hummus") != "hummus" || Stem("tea") != "tea" {
+		t.Fatal("expect hummus and tea unchanged")
+	}
+}
+
+func TestEditDistance(t *testing.T) {
+	cases := []struct {
+		a, b   string
+		max    int
+		expect int
+	}{
+		{"burrito", "burrito", 2, 0},
+		{"burito", "burrito", 2, 1},
+		{"burirto", "burrito", 2, 1},
+		{"brrto", "burrito", 2, 2},
+		{"pizza", "burrito", 2, 3},
+	}
+	for _, c := range cases {
+		if d := editDistance(c.a, c.b, c.max); d != c.expect {
+			t.Fatalf("editDistance(%s, %s) expect %d, got %d", c.a, c.b, c.expect, d)
+		}
+	}
+}
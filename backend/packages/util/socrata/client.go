@@ -0,0 +1,161 @@
+package socrata
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"food-trucks/packages/util/errs"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+const defaultPageSize = 1000
+
+// ErrNotModified is returned by FetchIfModified when the dataset is unchanged since the validator
+var ErrNotModified = errors.New("not modified")
+
+// Validator is the ETag and Last-Modified of a fetch, for conditional requests of the next one
+type Validator struct {
+	ETag         string
+	LastModified string
+}
+
+func (v Validator) IsZero() bool {
+	return v.ETag == "" && v.LastModified == ""
+}
+
+// Query narrows rows with SoQL, e.g. Where: "status = 'APPROVED'", Order keeps paging stable and defaults to ":id"
+type Query struct {
+	Where string
+	Order string
+}
+
+/*
+Client reads all rows of a dataset from a SODA api endpoint, e.g. https://data.sfgov.org/resource/rqzj-sfat.json,
+one page of $limit rows at a time.
+*/
+type Client struct {
+	url        string
+	appToken   string
+	pageSize   int
+	httpClient *http.Client
+}
+
+func NewClient(url string) *Client {
+	return &Client{
+		url:        url,
+		pageSize:   defaultPageSize,
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+func (c *Client) URL() string {
+	return c.url
+}
+
+// WithAppToken sets X-App-Token, requests without it are throttled by the portal
+func (c *Client) WithAppToken(token string) *Client {
+	c.appToken = token
+	return c
+}
+
+func (c *Client) WithPageSize(size int) *Client {
+	if size > 0 {
+		c.pageSize = size
+	}
+	return c
+}
+
+func (c *Client) WithHTTPClient(client *http.Client) *Client {
+	c.httpClient = client
+	return c
+}
+
+// Fetch returns rows of all pages, a page shorter than the page size is the last one
+func (c *Client) Fetch(ctx context.Context, query Query) ([]json.RawMessage, error) {
+	rows, _, err := c.FetchIfModified(ctx, query, Validator{})
+	return rows, err
+}
+
+/*
+FetchIfModified is Fetch with a conditional request of the first page, it returns ErrNotModified
+if the portal answers 304 to the validator of the last fetch. The portal's ETag changes with the dataset,
+so the first page stands for all of them.
+*/
+func (c *Client) FetchIfModified(ctx context.Context, query Query, since Validator) ([]json.RawMessage, Validator, error) {
+	var rows []json.RawMessage
+	var validator Validator
+	for offset := 0; ; offset += c.pageSize {
+		page, pageValidator, err := c.fetchPage(ctx, query, offset, since)
+		if err != nil {
+			return nil, validator, err
+		}
+		if offset == 0 {
+			validator, since = pageValidator, Validator{}
+		}
+		rows = append(rows, page...)
+		if len(page) < c.pageSize {
+			return rows, validator, nil
+		}
+	}
+}
+
+func (c *Client) fetchPage(ctx context.Context, query Query, offset int, since Validator) ([]json.RawMessage, Validator, error) {
+	var validator Validator
+	u, err := url.Parse(c.url)
+	if err != nil {
+		return nil, validator, err
+	}
+	params := u.Query()
+	params.Set("$limit", strconv.Itoa(c.pageSize))
+	params.Set("$offset", strconv.Itoa(offset))
+	if query.Order == "" {
+		query.Order = ":id"
+	}
+	params.Set("$order", query.Order)
+	if query.Where != "" {
+		params.Set("$where", query.Where)
+	}
+	u.RawQuery = params.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, validator, err
+	}
+	req.Header.Set("Accept", "application/json")
+	if c.appToken != "" {
+		req.Header.Set("X-App-Token", c.appToken)
+	}
+	if since.ETag != "" {
+		req.Header.Set("If-None-Match", since.ETag)
+	}
+	if since.LastModified != "" {
+		req.Header.Set("If-Modified-Since", since.LastModified)
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, validator, errs.Unavailable("socrata %s unreachable, %w", c.url, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotModified {
+		return nil, since, ErrNotModified
+	}
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		// throttled or failing upstream may succeed on retry
+		if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500 {
+			return nil, validator, errs.Unavailable("socrata %s responded %s: %s", c.url, resp.Status, body)
+		}
+		return nil, validator, fmt.Errorf("socrata %s responded %s: %s", c.url, resp.Status, body)
+	}
+	validator = Validator{ETag: resp.Header.Get("ETag"), LastModified: resp.Header.Get("Last-Modified")}
+	var page []json.RawMessage
+	if err = json.NewDecoder(resp.Body).Decode(&page); err != nil {
+		return nil, validator, fmt.Errorf("socrata %s returned invalid rows, %w", c.url, err)
+	}
+	return page, validator, nil
+}
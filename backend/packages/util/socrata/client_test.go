@@ -0,0 +1,102 @@
+package socrata
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"food-trucks/packages/util/errs"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+)
+
+// newFeed serves total rows like a SODA endpoint, records the queries it receives
+func newFeed(t *testing.T, total int, queries *[]map[string]string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		*queries = append(*queries, map[string]string{
+			"limit": q.Get("$limit"), "offset": q.Get("$offset"), "where": q.Get("$where"),
+			"order": q.Get("$order"), "token": r.Header.Get("X-App-Token"),
+		})
+		limit, _ := strconv.Atoi(q.Get("$limit"))
+		offset, _ := strconv.Atoi(q.Get("$offset"))
+		rows := make([]map[string]string, 0)
+		for i := offset; i < min(offset+limit, total); i++ {
+			rows = append(rows, map[string]string{"objectid": fmt.Sprint(i)})
+		}
+		if err := json.NewEncoder(w).Encode(rows); err != nil {
+			t.Error(err)
+		}
+	}))
+}
+
+func TestClient_Fetch(t *testing.T) {
+	var queries []map[string]string
+	feed := newFeed(t, 5, &queries)
+	defer feed.Close()
+
+	client := NewClient(feed.URL + "/resource/rqzj-sfat.json").WithPageSize(2).WithAppToken("token")
+	rows, err := client.Fetch(context.Background(), Query{Where: "status = 'APPROVED'"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 5 || len(queries) != 3 {
+		t.Fatalf("expect 5 rows in 3 pages, got %d rows in %d pages", len(rows), len(queries))
+	}
+	for i, q := range queries {
+		if q["limit"] != "2" || q["offset"] != strconv.Itoa(i*2) || q["where"] != "status = 'APPROVED'" ||
+			q["order"] != ":id" || q["token"] != "token" {
+			t.Fatalf("unexpected query %v", q)
+		}
+	}
+
+	// a full last page needs one more request to know it is the last
+	queries = nil
+	if rows, err = client.Fetch(context.Background(), Query{}); err != nil || len(rows) != 5 {
+		t.Fatalf("unexpected result %v %v", rows, err)
+	}
+	feed4 := newFeed(t, 4, &queries)
+	defer feed4.Close()
+	queries = nil
+	if rows, err = NewClient(feed4.URL).WithPageSize(2).Fetch(context.Background(), Query{}); err != nil || len(rows) != 4 || len(queries) != 3 {
+		t.Fatalf("unexpected result %d rows in %d pages, %v", len(rows), len(queries), err)
+	}
+}
+
+func TestClient_FetchError(t *testing.T) {
+	feed := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"message":"throttled"}`, http.StatusTooManyRequests)
+	}))
+	defer feed.Close()
+	if _, err := NewClient(feed.URL).Fetch(context.Background(), Query{}); errs.KindOf(err) != errs.KindUnavailable {
+		t.Fatalf("expect throttled response to be unavailable, got %v", err)
+	}
+}
+
+func TestClient_FetchIfModified(t *testing.T) {
+	etag := `"v1"`
+	feed := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") == etag {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", etag)
+		_, _ = w.Write([]byte(`[{"objectid":"1"}]`))
+	}))
+	defer feed.Close()
+
+	client := NewClient(feed.URL)
+	rows, validator, err := client.FetchIfModified(context.Background(), Query{}, Validator{})
+	if err != nil || len(rows) != 1 || validator.ETag != etag {
+		t.Fatalf("unexpected result %v %v %v", rows, validator, err)
+	}
+	if _, _, err = client.FetchIfModified(context.Background(), Query{}, validator); !errors.Is(err, ErrNotModified) {
+		t.Fatalf("expect not modified, got %v", err)
+	}
+	etag = `"v2"`
+	if rows, validator, err = client.FetchIfModified(context.Background(), Query{}, validator); err != nil || validator.ETag != etag {
+		t.Fatalf("expect changed feed fetched, got %v %v %v", rows, validator, err)
+	}
+}
@@ -0,0 +1,173 @@
+package validate
+
+import (
+	"fmt"
+	"food-trucks/packages/util/errs"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+/*
+Struct sets zero fields of the struct v points to from their `default` tag, then checks their `validate` tag, e.g.
+
+	Count int `url:"count" default:"10" validate:"min=1,max=100"`
+
+Rules are separated by ",": required is a non-zero value, min and max bound a number or the length of a string.
+A pointer field tells a missing parameter from a zero one: required is non-nil, min and max bound the value pointed to.
+Fields are named by their url tag, fields of embedded structs are checked too.
+All invalid fields are listed in one errs.KindInvalid error, a malformed tag is an internal error.
+*/
+func Struct(v any) error {
+	val := reflect.ValueOf(v)
+	if val.Kind() != reflect.Pointer || val.Elem().Kind() != reflect.Struct {
+		return errs.Errf("validate: %T is not a pointer to struct", v)
+	}
+	var fields []errs.FieldError
+	if err := check(val.Elem(), &fields); err != nil {
+		return err
+	}
+	if len(fields) > 0 {
+		return errs.InvalidFields(fields...)
+	}
+	return nil
+}
+
+func check(val reflect.Value, fields *[]errs.FieldError) error {
+	typ := val.Type()
+	for i := 0; i < typ.NumField(); i++ {
+		field, value := typ.Field(i), val.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		if field.Anonymous && value.Kind() == reflect.Struct {
+			if err := check(value, fields); err != nil {
+				return err
+			}
+			continue
+		}
+		name := fieldName(field)
+		if def, ok := field.Tag.Lookup("default"); ok && value.IsZero() {
+			if err := setDefault(value, def); err != nil {
+				return errs.Errf("validate: default of %s, %w", name, err)
+			}
+		}
+		rules, ok := field.Tag.Lookup("validate")
+		if !ok {
+			continue
+		}
+		for _, rule := range strings.Split(rules, ",") {
+			reason, err := checkRule(value, strings.TrimSpace(rule))
+			if err != nil {
+				return errs.Errf("validate: rule of %s, %w", name, err)
+			}
+			if reason != "" {
+				*fields = append(*fields, errs.FieldError{Field: name, Reason: reason})
+				break
+			}
+		}
+	}
+	return nil
+}
+
+func fieldName(field reflect.StructField) string {
+	if name, _, _ := strings.Cut(field.Tag.Get("url"), ","); name != "" {
+		return name
+	}
+	return field.Name
+}
+
+// checkRule returns why value breaks the rule, empty if it doesn't
+func checkRule(value reflect.Value, rule string) (string, error) {
+	name, arg, _ := strings.Cut(rule, "=")
+	switch name {
+	case "":
+		return "", nil
+	case "required":
+		if value.IsZero() {
+			return "is required", nil
+		}
+		return "", nil
+	case "min", "max":
+		if value.Kind() == reflect.Pointer {
+			if value.IsNil() {
+				return "", nil
+			}
+			value = value.Elem()
+		}
+		bound, err := strconv.ParseFloat(arg, 64)
+		if err != nil {
+			return "", err
+		}
+		n, err := measure(value)
+		if err != nil {
+			return "", err
+		}
+		unit := ""
+		if value.Kind() == reflect.String {
+			unit = " characters"
+		}
+		if name == "min" && n < bound {
+			return fmt.Sprintf("should be at least %s%s", arg, unit), nil
+		}
+		if name == "max" && n > bound {
+			return fmt.Sprintf("should be at most %s%s", arg, unit), nil
+		}
+		return "", nil
+	default:
+		return "", fmt.Errorf("unknown rule %q", rule)
+	}
+}
+
+// measure is the number min and max bound
+func measure(value reflect.Value) (float64, error) {
+	switch value.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(value.Int()), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(value.Uint()), nil
+	case reflect.Float32, reflect.Float64:
+		return value.Float(), nil
+	case reflect.String:
+		return float64(len([]rune(value.String()))), nil
+	default:
+		return 0, fmt.Errorf("can't bound %s", value.Kind())
+	}
+}
+
+func setDefault(value reflect.Value, def string) error {
+	switch value.Kind() {
+	case reflect.Pointer:
+		value.Set(reflect.New(value.Type().Elem()))
+		return setDefault(value.Elem(), def)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(def, 10, 64)
+		if err != nil {
+			return err
+		}
+		value.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(def, 10, 64)
+		if err != nil {
+			return err
+		}
+		value.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(def, 64)
+		if err != nil {
+			return err
+		}
+		value.SetFloat(f)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(def)
+		if err != nil {
+			return err
+		}
+		value.SetBool(b)
+	case reflect.String:
+		value.SetString(def)
+	default:
+		return fmt.Errorf("can't default %s", value.Kind())
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+package validate
+
+import (
+	"food-trucks/packages/util/errs"
+	"testing"
+)
+
+type PageQuery struct {
+	Limit int `url:"limit" default:"10" validate:"min=1,max=100"`
+}
+
+type nearQuery struct {
+	Item   string  `url:"item" validate:"required,max=20"`
+	Lat    float64 `url:"lat" validate:"min=-90,max=90"`
+	Radius float64 `url:"radius" default:"1.5" validate:"min=0,max=50"`
+	PageQuery
+	ignored int
+}
+
+func TestStruct(t *testing.T) {
+	q := nearQuery{Item: "tacos", Lat: 37.7}
+	if err := Struct(&q); err != nil {
+		t.Fatal(err)
+	}
+	if q.Radius != 1.5 || q.Limit != 10 {
+		t.Fatalf("expect defaults set, got %+v", q)
+	}
+
+	q = nearQuery{Lat: 500, Radius: -1, PageQuery: PageQuery{Limit: 1000}}
+	err := Struct(&q)
+	if errs.KindOf(err) != errs.KindInvalid {
+		t.Fatalf("expect invalid, got %v", err)
+	}
+	fields := errs.FieldsOf(err)
+	want := []errs.FieldError{
+		{Field: "item", Reason: "is required"},
+		{Field: "lat", Reason: "should be at most 90"},
+		{Field: "radius", Reason: "should be at least 0"},
+		{Field: "limit", Reason: "should be at most 100"},
+	}
+	if len(fields) != len(want) {
+		t.Fatalf("expect %v, got %v", want, fields)
+	}
+	for i := range want {
+		if fields[i] != want[i] {
+			t.Fatalf("expect %v, got %v", want, fields)
+		}
+	}
+}
+
+func TestStruct_BadTag(t *testing.T) {
+	q := struct {
+		Lat float64 `url:"lat" validate:"between=1"`
+	}{}
+	if err := Struct(&q); err == nil || errs.KindOf(err) != errs.KindInternal {
+		t.Fatalf("expect internal error, got %v", err)
+	}
+	if err := Struct(q); err == nil {
+		t.Fatal("expect error for non pointer")
+	}
+}
+
+func TestStruct_Pointer(t *testing.T) {
+	zero, far := 0.0, 200.0
+	q := struct {
+		Lat    *float64 `url:"lat" validate:"required,min=-90,max=90"`
+		Lon    *float64 `url:"lon" validate:"min=-180,max=180"`
+		Radius *float64 `url:"radius" default:"1"`
+	}{Lat: &zero}
+	if err := Struct(&q); err != nil {
+		t.Fatalf("expect lat 0 valid, got %v", err)
+	}
+	if q.Lon != nil || q.Radius == nil || *q.Radius != 1 {
+		t.Fatalf("expect lon missing and radius defaulted, got %+v", q)
+	}
+
+	q.Lat, q.Lon = nil, &far
+	fields := errs.FieldsOf(Struct(&q))
+	want := []errs.FieldError{
+		{Field: "lat", Reason: "is required"},
+		{Field: "lon", Reason: "should be at most 180"},
+	}
+	if len(fields) != len(want) || fields[0] != want[0] || fields[1] != want[1] {
+		t.Fatalf("expect %v, got %v", want, fields)
+	}
+}
@@ -1,27 +1,26 @@
 import {Marker, Popup, useMap, useMapEvents} from "react-leaflet";
-import {getDistanceFromLatLonInKm} from "../utils/getDistance";
 import useSWR from "swr";
 import {Config} from "../config";
 import {fetcher} from "../utils/fetcher";
-import {useState} from "react";
+import {useEffect, useState} from "react";
 
 export  function FacilityMarkers(){
-    const [location, setLocation] = useState<any>({lat:0, lng:0, radius:0})
-    const getLocation = ()=> {
-        const {lat, lng} = map.getCenter()
-        const se = map.getBounds().getSouthEast()
-        const radius = getDistanceFromLatLonInKm(lat, lng, se.lat, se.lng)
-        setLocation({ lat,lng,radius})
+    const [bounds, setBounds] = useState<any>(null)
+    const getBounds = ()=> {
+        const b = map.getBounds()
+        setBounds({minLat: b.getSouth(), minLon: Math.max(b.getWest(), -180), maxLat: b.getNorth(), maxLon: Math.min(b.getEast(), 180)})
     }
     const map = useMapEvents({
         zoomend:(e) => {
-            getLocation()
+            getBounds()
         },
         moveend:(e) => {
-            getLocation()
+            getBounds()
         }
     })
-    const {data : facilities} = useSWR<Facility[]>(Config.APIHost + `/api/facilities?lat=${location.lat}&lon=${location.lng}&radius=${location.radius}`, fetcher)
+    useEffect(() => getBounds(), [])
+    const {data : facilities} = useSWR<Facility[]>(bounds && Config.APIHost +
+        `/api/facilities/bbox?minLat=${bounds.minLat}&minLon=${bounds.minLon}&maxLat=${bounds.maxLat}&maxLon=${bounds.maxLon}`, fetcher)
     return facilities &&<>
         {facilities.map(item => {
            return <Marker key={item.locationID} position={[item.latitude, item.longitude]} >
@@ -8,13 +8,13 @@ import {Button} from "primereact/button";
 import {SwitchLocation} from "./SwitchLocation";
 
 export default function Map() {
-    const {data: center} = useSWR(Config.APIHost + '/api/facilities/center', fetcher)
+    const {data: extent} = useSWR(Config.APIHost + '/api/facilities/extent', fetcher)
     const [your, setYour] = useState(false)
 
     return (
         <div>
             {!your && <Button onClick={()=> setYour(true)} label="Your Location" style={{ backgroundColor: 'var(--primary-color)', color: 'var(--primary-color-text)'}}/>}
-            {center && <MapContainer center={[center.Lat, center.Lon]} zoom={18} scrollWheelZoom={false}>
+            {extent?.center && <MapContainer center={[extent.center.lat, extent.center.lon]} zoom={extent.zoom} scrollWheelZoom={false}>
                 <TileLayer
                     attribution='&copy; <a href="https://www.openstreetmap.org/copyright">OpenStreetMap</a> contributors'
                     url="https://{s}.tile.openstreetmap.org/{z}/{x}/{y}.png"